@@ -0,0 +1,161 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+ * ACCOUNT MANAGER
+ */
+
+var ErrUnknownAccount = errors.New("lichess: unknown account")
+
+// AccountError ties an error to the managed account it happened on.
+type AccountError struct {
+	Account string
+	Err error
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("account %s: %v", e.Account, e.Err)
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// AccountEvent is an event received on the stream of a managed account.
+type AccountEvent struct {
+	Account string
+	Event Event
+}
+
+// AccountManager holds the clients of several accounts, such as a fleet of
+// bots or the accounts of an event organizer, and routes calls to them by
+// name. It is safe for concurrent use.
+type AccountManager struct {
+	mu sync.RWMutex
+	accounts map[string]*managedAccount
+}
+
+type managedAccount struct {
+	client *Lichess
+	limiter *Limiter
+}
+
+func NewAccountManager() *AccountManager {
+	return &AccountManager{accounts: map[string]*managedAccount{}}
+}
+
+// Add registers client under name, replacing any previous account of that
+// name. Calls made through Do on this account are spaced at least interval
+// apart, 0 disables the limit.
+func (m *AccountManager) Add(name string, client *Lichess, interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts[name] = &managedAccount{client, NewLimiter(interval)}
+}
+
+func (m *AccountManager) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.accounts, name)
+}
+
+func (m *AccountManager) Get(name string) (*Lichess, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	account, ok := m.accounts[name]
+	if !ok {
+		return nil, false
+	}
+	return account.client, true
+}
+
+// Names returns the registered account names in sorted order.
+func (m *AccountManager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.accounts))
+	for name := range m.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Do runs fn with the client of the named account once its rate limit
+// allows. Errors returned by fn are wrapped in an *AccountError.
+func (m *AccountManager) Do(ctx context.Context, name string, fn func(context.Context, *Lichess) error) error {
+	m.mu.RLock()
+	account, ok := m.accounts[name]
+	m.mu.RUnlock()
+	if !ok {
+		return &AccountError{name, ErrUnknownAccount}
+	}
+
+	if err := account.limiter.Wait(ctx); err != nil {
+		return &AccountError{name, err}
+	}
+	if err := fn(ctx, account.client); err != nil {
+		return &AccountError{name, err}
+	}
+	return nil
+}
+
+// StreamEvents follows the event stream of every registered account
+// concurrently. Both channels are closed once all streams have ended, which
+// happens when ctx is cancelled or every stream failed; failures are
+// reported as *AccountError on the error channel.
+func (m *AccountManager) StreamEvents(ctx context.Context) (<-chan AccountEvent, <-chan error) {
+	m.mu.RLock()
+	accounts := make(map[string]*Lichess, len(m.accounts))
+	for name, account := range m.accounts {
+		accounts[name] = account.client
+	}
+	m.mu.RUnlock()
+
+	out := make(chan AccountEvent)
+	errs := make(chan error, len(accounts))
+
+	var wg sync.WaitGroup
+	for name, client := range accounts {
+		wg.Add(1)
+		go func(name string, client *Lichess) {
+			defer wg.Done()
+
+			events := make(chan Event)
+			done := make(chan error, 1)
+			go func() {
+				done <- client.StreamEvents(ctx, events)
+			}()
+
+			for {
+				select {
+				case event := <-events:
+					select {
+					case out <- AccountEvent{name, event}:
+					case <-ctx.Done():
+					}
+				case err := <-done:
+					if err != nil && ctx.Err() == nil {
+						errs <- &AccountError{name, err}
+					}
+					return
+				}
+			}
+		}(name, client)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+		close(errs)
+	}()
+	return out, errs
+}
@@ -0,0 +1,667 @@
+package lichess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * ARENAS
+ */
+
+// GET
+const arenasPath = "/api/tournament"
+const arenaPath = "/api/tournament/%s" // ArenaID
+const arenaResultsPath = "/api/tournament/%s/results" // ArenaID
+const arenaGamesPath = "/api/tournament/%s/games" // ArenaID
+const teamStandingPath = "/api/tournament/%s/teams" // ArenaID
+const arenasCreatedByPath = "/api/user/%s/tournament/created" // Username
+const arenasPlayedByPath = "/api/user/%s/tournament/played" // Username
+
+// POST
+const createArenaPath = "/api/tournament"
+const joinArenaPath = "/api/tournament/%s/join" // ArenaID
+const withdrawArenaPath = "/api/tournament/%s/withdraw" // ArenaID
+const teamBattlePath = "/api/tournament/team-battle/%s" // ArenaID
+
+type Arena struct {
+	ID string `json:"id"`
+	CreatedBy string `json:"createdBy"`
+	System string `json:"system"`
+	Minutes int `json:"minutes"`
+	Clock ArenaClock `json:"clock"`
+	Rated bool `json:"rated"`
+	FullName string `json:"fullName"`
+	NbPlayers int `json:"nbPlayers"`
+	Variant Variant `json:"variant"`
+	// StartsAt and FinishesAt are in milliseconds since the epoch
+	StartsAt int64 `json:"startsAt"`
+	FinishesAt int64 `json:"finishesAt"`
+	Status int `json:"status"`
+	Perf ArenaPerf `json:"perf"`
+	SecondsToStart int `json:"secondsToStart,omitempty"`
+	Schedule *ArenaSchedule `json:"schedule,omitempty"`
+	Winner *LightUser `json:"winner,omitempty"`
+	TeamBattle *TeamBattle `json:"teamBattle,omitempty"`
+	// Verdicts tells whether the authenticated user meets the entry
+	// conditions, sent with the details of restricted tournaments
+	Verdicts *ArenaVerdicts `json:"verdicts,omitempty"`
+}
+
+type ArenaVerdicts struct {
+	Accepted bool `json:"accepted"`
+	List []ArenaVerdict `json:"list"`
+}
+
+// ArenaVerdict is a condition of a tournament, as displayed, with "ok" or
+// the reason the user does not meet it.
+type ArenaVerdict struct {
+	Condition string `json:"condition"`
+	Verdict string `json:"verdict"`
+}
+
+// TeamBattle is set on the tournaments played between teams.
+type TeamBattle struct {
+	// Teams holds the names of the teams, by ID
+	Teams map[string]string `json:"teams"`
+	// NbLeaders is how many of the best players of each team score
+	NbLeaders int `json:"nbLeaders"`
+}
+
+// UnmarshalJSON reads the dates as sent by both the tournament list, in
+// milliseconds, and the tournament details, as RFC 3339 strings.
+func (a *Arena) UnmarshalJSON(data []byte) error {
+	var v arenaJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = Arena(v.arenaFields)
+	var err error
+	if a.StartsAt, err = parseMillis(v.StartsAt); err != nil {
+		return err
+	}
+	a.FinishesAt, err = parseMillis(v.FinishesAt)
+	return err
+}
+
+// arenaJSON is an Arena with its dates in either form.
+type arenaJSON struct {
+	arenaFields
+	StartsAt json.RawMessage `json:"startsAt"`
+	FinishesAt json.RawMessage `json:"finishesAt"`
+}
+
+type arenaFields Arena
+
+func (*Arena) jsonAlias() interface{} { return &arenaJSON{} }
+
+// parseMillis reads a date given either in milliseconds since the epoch or
+// as an RFC 3339 string.
+func parseMillis(raw json.RawMessage) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	if raw[0] != '"' {
+		var ms int64
+		err := json.Unmarshal(raw, &ms)
+		return ms, err
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixMilli(), nil
+}
+
+// Arena statuses
+const (
+	ArenaCreated = 10
+	ArenaStarted = 20
+	ArenaFinished = 30
+)
+
+// ArenaClock is the time control of a tournament, in seconds.
+type ArenaClock struct {
+	Limit int `json:"limit"`
+	Increment int `json:"increment"`
+}
+
+func (c ArenaClock) String() string {
+	return FormatTimeControl(time.Duration(c.Limit)*time.Second, time.Duration(c.Increment)*time.Second)
+}
+
+type ArenaPerf struct {
+	Key string `json:"key"`
+	Name string `json:"name"`
+	Position int `json:"position"`
+}
+
+// ArenaSchedule is set on the tournaments Lichess schedules itself, e.g.
+// hourly or monthly arenas.
+type ArenaSchedule struct {
+	Freq string `json:"freq"`
+	Speed string `json:"speed"`
+}
+
+// Official reports whether the tournament is organized by Lichess.
+func (a Arena) Official() bool {
+	return a.CreatedBy == "lichess" || a.Schedule != nil
+}
+
+func (a Arena) StartTime() time.Time {
+	return time.UnixMilli(a.StartsAt)
+}
+
+// Countdown returns the time left before the tournament starts.
+func (a Arena) Countdown() time.Duration {
+	return time.Until(a.StartTime())
+}
+
+// ArenaList holds the current tournaments, grouped by status.
+type ArenaList struct {
+	Created []Arena `json:"created"`
+	Started []Arena `json:"started"`
+	Finished []Arena `json:"finished"`
+}
+
+// GetArenas returns the recently finished, ongoing and upcoming arenas.
+func (l *Lichess) GetArenas(ctx context.Context) (ArenaList, error) {
+	arenas := ArenaList{}
+	err := l.getJSON(ctx, arenasPath, &arenas)
+	return arenas, err
+}
+
+// GetCurrentTournaments returns the created, started and finished arenas, as
+// GetArenas does, e.g. for an upcoming-events screen.
+func (l *Lichess) GetCurrentTournaments(ctx context.Context) (ArenaList, error) {
+	return l.GetArenas(ctx)
+}
+
+// ArenaFilter selects upcoming tournaments, zero fields match everything.
+type ArenaFilter struct {
+	Variant VariantKey
+	Speed Speed
+	Clock *ArenaClock
+	// All includes tournaments created by users
+	All bool
+}
+
+func (f ArenaFilter) match(a Arena) bool {
+	switch {
+	case !f.All && !a.Official():
+		return false
+	case f.Variant != "" && a.Variant.Key != f.Variant:
+		return false
+	case f.Clock != nil && a.Clock != *f.Clock:
+		return false
+	case f.Speed != "":
+		initial := time.Duration(a.Clock.Limit) * time.Second
+		increment := time.Duration(a.Clock.Increment) * time.Second
+		return SpeedOf(initial, increment) == f.Speed
+	}
+	return true
+}
+
+// UpcomingArenas returns the tournaments not started yet that match filter,
+// soonest first.
+func (l *Lichess) UpcomingArenas(ctx context.Context, filter ArenaFilter) ([]Arena, error) {
+	list, err := l.GetArenas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var upcoming []Arena
+	for _, a := range list.Created {
+		if filter.match(a) {
+			upcoming = append(upcoming, a)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].StartsAt < upcoming[j].StartsAt
+	})
+	return upcoming, nil
+}
+
+// The arena list is refreshed this often while waiting for tournaments.
+const arenaRefresh = 5 * time.Minute
+
+// WatchArenas sends every upcoming tournament matching filter on the returned
+// channel once it starts in less than before. The list is refreshed
+// periodically, the channel is closed when ctx is done. Errors fetching the
+// list are retried at the next refresh.
+func (l *Lichess) WatchArenas(ctx context.Context, filter ArenaFilter, before time.Duration) <-chan Arena {
+	ch := make(chan Arena)
+	go func() {
+		defer close(ch)
+		notified := map[string]bool{}
+		var upcoming []Arena
+		refreshAt := time.Now()
+		for {
+			if !time.Now().Before(refreshAt) {
+				if arenas, err := l.UpcomingArenas(ctx, filter); err == nil {
+					upcoming = arenas
+				}
+				refreshAt = time.Now().Add(arenaRefresh)
+			}
+
+			wake := refreshAt
+			for _, a := range upcoming {
+				if notified[a.ID] {
+					continue
+				}
+				notifyAt := a.StartTime().Add(-before)
+				if time.Now().Before(notifyAt) {
+					if notifyAt.Before(wake) {
+						wake = notifyAt
+					}
+					continue
+				}
+				if time.Now().After(a.StartTime()) {
+					continue
+				}
+				select {
+				case ch <- a:
+					notified[a.ID] = true
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-time.After(time.Until(wake)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ArenaOptions describes a tournament to create. Name, clock and duration
+// are required, the zero values of the other fields select the defaults of
+// Lichess.
+type ArenaOptions struct {
+	// Name is suffixed with "Arena" by Lichess
+	Name string
+	Clock ArenaClock
+	// Minutes is the duration of the tournament
+	Minutes int
+	// StartsAt is the start date, the tournament otherwise starts
+	// WaitMinutes after its creation
+	StartsAt time.Time
+	WaitMinutes int
+	Variant string
+	// Position is the FEN of the initial position, of standard games only
+	Position string
+	Casual bool
+	NoBerserk bool
+	NoStreak bool
+	NoChat bool
+	Description string
+	// Password makes the tournament private
+	Password string
+	Conditions ArenaConditions
+	// TeamBattleByTeam makes the tournament a team battle, the teams are
+	// then set with UpdateTeamBattle
+	TeamBattleByTeam string
+}
+
+// ArenaConditions restrict who may join a tournament.
+type ArenaConditions struct {
+	MinRating int
+	MaxRating int
+	MinRatedGames int
+	// MinAccountAge is in days, Lichess accepting 1, 3, 7, 14, 30, 60, 90,
+	// 180, 365, 730 and 1095
+	MinAccountAge int
+	// Team restricts the tournament to the members of a team, by ID
+	Team string
+}
+
+func (o ArenaOptions) values() url.Values {
+	v := url.Values{}
+	v.Set("name", o.Name)
+	v.Set("clockTime", strconv.FormatFloat(float64(o.Clock.Limit)/60, 'f', -1, 64))
+	v.Set("clockIncrement", strconv.Itoa(o.Clock.Increment))
+	v.Set("minutes", strconv.Itoa(o.Minutes))
+	if !o.StartsAt.IsZero() {
+		v.Set("startDate", strconv.FormatInt(o.StartsAt.UnixMilli(), 10))
+	}
+	if o.WaitMinutes > 0 {
+		v.Set("waitMinutes", strconv.Itoa(o.WaitMinutes))
+	}
+	if o.Variant != "" {
+		v.Set("variant", o.Variant)
+	}
+	if o.Position != "" {
+		v.Set("position", o.Position)
+	}
+	v.Set("rated", strconv.FormatBool(!o.Casual))
+	v.Set("berserkable", strconv.FormatBool(!o.NoBerserk))
+	v.Set("streakable", strconv.FormatBool(!o.NoStreak))
+	v.Set("hasChat", strconv.FormatBool(!o.NoChat))
+	if o.Description != "" {
+		v.Set("description", o.Description)
+	}
+	if o.Password != "" {
+		v.Set("password", o.Password)
+	}
+	if o.TeamBattleByTeam != "" {
+		v.Set("teamBattleByTeam", o.TeamBattleByTeam)
+	}
+	c := o.Conditions
+	if c.MinRating > 0 {
+		v.Set("conditions.minRating.rating", strconv.Itoa(c.MinRating))
+	}
+	if c.MaxRating > 0 {
+		v.Set("conditions.maxRating.rating", strconv.Itoa(c.MaxRating))
+	}
+	if c.MinRatedGames > 0 {
+		v.Set("conditions.nbRatedGame.nb", strconv.Itoa(c.MinRatedGames))
+	}
+	if c.MinAccountAge > 0 {
+		v.Set("conditions.accountAge", strconv.Itoa(c.MinAccountAge))
+	}
+	if c.Team != "" {
+		v.Set("conditions.teamMember.teamId", c.Team)
+	}
+	return v
+}
+
+// CreateArena creates an arena tournament organized by the authenticated
+// user.
+func (l *Lichess) CreateArena(ctx context.Context, opts ArenaOptions) (Arena, error) {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return Arena{}, err
+	}
+	arena := Arena{}
+	err := l.postFormJSON(ctx, createArenaPath, opts.values(), &arena)
+	return arena, err
+}
+
+// JoinArena enters the authenticated user in a tournament. password is only
+// needed for private tournaments and team for team battles, by team ID. In a
+// started tournament, joining again after WithdrawArena resumes pairing.
+// Entries refused by Lichess are returned as an *ArenaEntryError.
+func (l *Lichess) JoinArena(ctx context.Context, id string, password string, team string) error {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return err
+	}
+	v := url.Values{}
+	if password != "" {
+		v.Set("password", password)
+	}
+	if team != "" {
+		v.Set("team", team)
+	}
+	err := l.postForm(ctx, fmt.Sprintf(joinArenaPath, url.PathEscape(id)), v)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return &ArenaEntryError{ArenaID: id, Reason: entryRefusal(apiErr.Message), Message: apiErr.Message}
+	}
+	return err
+}
+
+// ErrArenaEntryRefused is matched by the errors of tournaments the user
+// could not join.
+var ErrArenaEntryRefused = errors.New("lichess: arena entry refused")
+
+// EntryRefusal is why Lichess refused an entry, guessed from its message.
+type EntryRefusal string
+
+const (
+	RefusalRatingTooLow EntryRefusal = "ratingTooLow"
+	RefusalRatingTooHigh EntryRefusal = "ratingTooHigh"
+	RefusalNotEnoughGames EntryRefusal = "notEnoughGames"
+	RefusalAccountTooNew EntryRefusal = "accountTooNew"
+	RefusalNotTeamMember EntryRefusal = "notTeamMember"
+	RefusalTitledOnly EntryRefusal = "titledOnly"
+	RefusalWrongPassword EntryRefusal = "wrongPassword"
+	// RefusalOther is any other reason, such as the tournament being over
+	RefusalOther EntryRefusal = "other"
+)
+
+type ArenaEntryError struct {
+	ArenaID string
+	Reason EntryRefusal
+	// Message is the explanation of Lichess, in the user's language, for
+	// display
+	Message string
+}
+
+func (e *ArenaEntryError) Error() string {
+	return fmt.Sprintf("%v: %s: %s", ErrArenaEntryRefused, e.ArenaID, e.Message)
+}
+
+func (e *ArenaEntryError) Is(target error) bool {
+	return target == ErrArenaEntryRefused
+}
+
+// entryRefusal reads the verdict Lichess sends in English, other languages
+// giving RefusalOther.
+func entryRefusal(message string) EntryRefusal {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "too low"):
+		return RefusalRatingTooLow
+	case strings.Contains(m, "too high"):
+		return RefusalRatingTooHigh
+	case strings.Contains(m, "rated game"):
+		return RefusalNotEnoughGames
+	case strings.Contains(m, "account"):
+		return RefusalAccountTooNew
+	case strings.Contains(m, "team"):
+		return RefusalNotTeamMember
+	case strings.Contains(m, "titled"):
+		return RefusalTitledOnly
+	case strings.Contains(m, "password") || strings.Contains(m, "entry code"):
+		return RefusalWrongPassword
+	}
+	return RefusalOther
+}
+
+// WithdrawArena leaves a tournament that has not started yet. Once it has
+// started the user is only paused, keeping its score, until JoinArena is
+// called again.
+func (l *Lichess) WithdrawArena(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(withdrawArenaPath, url.PathEscape(id)), nil)
+}
+
+// ArenaInfo is a tournament with its state and a page of its standings.
+type ArenaInfo struct {
+	Arena
+	IsStarted bool `json:"isStarted"`
+	IsFinished bool `json:"isFinished"`
+	SecondsToFinish int `json:"secondsToFinish"`
+	Standing ArenaStanding `json:"standing"`
+}
+
+type ArenaStanding struct {
+	Page int `json:"page"`
+	Players []ArenaStandingPlayer `json:"players"`
+}
+
+type ArenaStandingPlayer struct {
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Rank int `json:"rank"`
+	Rating int `json:"rating"`
+	Score int `json:"score"`
+	// Team is the team ID of the player in team battles
+	Team string `json:"team,omitempty"`
+	Withdraw bool `json:"withdraw,omitempty"`
+	Sheet ArenaSheet `json:"sheet"`
+}
+
+// ArenaSheet holds the points scored in each game, most recent first, and
+// whether the player is on a winning streak.
+type ArenaSheet struct {
+	Scores string `json:"scores"`
+	Fire bool `json:"fire,omitempty"`
+}
+
+// UnmarshalJSON is needed as the one of Arena would otherwise be promoted.
+func (a *ArenaInfo) UnmarshalJSON(data []byte) error {
+	if err := a.Arena.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	v := arenaInfoState{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	a.IsStarted, a.IsFinished, a.SecondsToFinish, a.Standing = v.IsStarted, v.IsFinished, v.SecondsToFinish, v.Standing
+	return nil
+}
+
+// arenaInfoState holds the fields ArenaInfo adds to Arena.
+type arenaInfoState struct {
+	IsStarted bool `json:"isStarted"`
+	IsFinished bool `json:"isFinished"`
+	SecondsToFinish int `json:"secondsToFinish"`
+	Standing ArenaStanding `json:"standing"`
+}
+
+func (*ArenaInfo) jsonAlias() interface{} {
+	return &struct {
+		arenaJSON
+		arenaInfoState
+	}{}
+}
+
+// GetArena returns a tournament along with a page of its standings, of 10
+// players each, the first page being 1.
+func (l *Lichess) GetArena(ctx context.Context, id string, page int) (ArenaInfo, error) {
+	path := fmt.Sprintf(arenaPath, url.PathEscape(id))
+	if page > 1 {
+		path += "?page=" + strconv.Itoa(page)
+	}
+	arena := ArenaInfo{}
+	err := l.getJSON(ctx, path, &arena)
+	return arena, err
+}
+
+// ArenaResult is the standing of a player at the end of a tournament.
+type ArenaResult struct {
+	Rank int `json:"rank"`
+	Score int `json:"score"`
+	Rating int `json:"rating"`
+	Username string `json:"username"`
+	Title string `json:"title,omitempty"`
+	Performance int `json:"performance"`
+	Team string `json:"team,omitempty"`
+}
+
+// StreamArenaResults streams the standings of a tournament, best first, up
+// to nb players or all of them if nb is zero.
+func (l *Lichess) StreamArenaResults(ctx context.Context, id string, nb int) *Stream[ArenaResult] {
+	path := fmt.Sprintf(arenaResultsPath, url.PathEscape(id))
+	if nb > 0 {
+		path += "?nb=" + strconv.Itoa(nb)
+	}
+	return newStream(ctx, l, streamSpec[ArenaResult]{path: path})
+}
+
+// StreamArenaGames streams the games of a tournament as they are exported,
+// set opts.PGNInJSON to get the PGN of each game along with it.
+func (l *Lichess) StreamArenaGames(ctx context.Context, id string, opts ExportOptions) *Stream[GameSummary] {
+	path := withQuery(fmt.Sprintf(arenaGamesPath, url.PathEscape(id)), opts.values())
+	return newStream(ctx, l, streamSpec[GameSummary]{path: path})
+}
+
+// ExportArenaGamesTo copies the games of a tournament straight into w, in the
+// format of opts.
+func (l *Lichess) ExportArenaGamesTo(ctx context.Context, id string, opts ExportOptions, w io.Writer) (int64, error) {
+	path := withQuery(fmt.Sprintf(arenaGamesPath, url.PathEscape(id)), opts.values())
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", opts.accept())
+	return l.copyTo(req, w)
+}
+
+// UpdateTeamBattle sets the teams of a team battle, by ID, and how many
+// leaders of each team score.
+func (l *Lichess) UpdateTeamBattle(ctx context.Context, id string, teams []string, nbLeaders int) (Arena, error) {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return Arena{}, err
+	}
+	v := url.Values{}
+	v.Set("teams", strings.Join(teams, ","))
+	v.Set("nbLeaders", strconv.Itoa(nbLeaders))
+	arena := Arena{}
+	err := l.postFormJSON(ctx, fmt.Sprintf(teamBattlePath, url.PathEscape(id)), v, &arena)
+	return arena, err
+}
+
+// TeamStanding is the standing of a team in a team battle, with its leaders.
+type TeamStanding struct {
+	Rank int `json:"rank"`
+	ID string `json:"id"`
+	Score int `json:"score"`
+	Players []TeamStandingPlayer `json:"players"`
+}
+
+type TeamStandingPlayer struct {
+	User LightUser `json:"user"`
+	Score int `json:"score"`
+}
+
+// GetTeamStanding returns the standings of the teams of a team battle, best
+// first.
+func (l *Lichess) GetTeamStanding(ctx context.Context, id string) ([]TeamStanding, error) {
+	resp := struct {
+		Teams []TeamStanding `json:"teams"`
+	}{}
+	err := l.getJSON(ctx, fmt.Sprintf(teamStandingPath, url.PathEscape(id)), &resp)
+	return resp.Teams, err
+}
+
+// StreamArenasCreatedBy streams the tournaments created by username, most
+// recent first, only those of the given statuses if any.
+func (l *Lichess) StreamArenasCreatedBy(ctx context.Context, username string, statuses ...int) *Stream[Arena] {
+	v := url.Values{}
+	for _, status := range statuses {
+		v.Add("status", strconv.Itoa(status))
+	}
+	path := withQuery(fmt.Sprintf(arenasCreatedByPath, url.PathEscape(username)), v)
+	return newStream(ctx, l, streamSpec[Arena]{path: path})
+}
+
+// PlayedArena is a tournament along with the results of the player.
+type PlayedArena struct {
+	Tournament Arena `json:"tournament"`
+	Player ArenaPlayerResult `json:"player"`
+}
+
+type ArenaPlayerResult struct {
+	Games int `json:"games"`
+	Score int `json:"score"`
+	Rank int `json:"rank"`
+	Performance int `json:"performance,omitempty"`
+}
+
+// StreamArenasPlayedBy streams the tournaments username played, most recent
+// first, up to nb or all of them if nb is zero.
+func (l *Lichess) StreamArenasPlayedBy(ctx context.Context, username string, nb int) *Stream[PlayedArena] {
+	path := fmt.Sprintf(arenasPlayedByPath, url.PathEscape(username))
+	if nb > 0 {
+		path += "?nb=" + strconv.Itoa(nb)
+	}
+	return newStream(ctx, l, streamSpec[PlayedArena]{path: path})
+}
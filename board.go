@@ -0,0 +1,165 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+/*
+ * BOARD CONTROLS
+ */
+
+// ErrMoveRejected is matched by the errors of moves Lichess refused.
+var ErrMoveRejected = errors.New("lichess: move rejected")
+
+// MoveRejectedError is returned for an illegal move, or one played out of
+// turn or after the game ended.
+type MoveRejectedError struct {
+	GameID string
+	Move string
+	Reason string
+}
+
+func (e *MoveRejectedError) Error() string {
+	return fmt.Sprintf("%v: %s in game %s: %s", ErrMoveRejected, e.Move, e.GameID, e.Reason)
+}
+
+func (e *MoveRejectedError) Is(target error) bool {
+	return target == ErrMoveRejected
+}
+
+// client returns the client the game was received with.
+func (g *Game) client() *Lichess {
+	if g.lichess == nil {
+		return &Lichess{}
+	}
+	return g.lichess
+}
+
+// MakeMove plays move, in UCI notation, optionally offering or agreeing to a
+// draw along with it. The game must come from the event stream, a session or
+// a challenge of an authenticated client.
+func (g *Game) MakeMove(ctx context.Context, move string, offeringDraw bool) error {
+	return g.client().makeMove(ctx, g.ID, move, offeringDraw)
+}
+
+func (l *Lichess) makeMove(ctx context.Context, gameID string, move string, offeringDraw bool) error {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return err
+	}
+	path := l.playPath(fmt.Sprintf(boardMovePath, url.PathEscape(gameID), url.PathEscape(move)))
+	if offeringDraw {
+		path += "?offeringDraw=true"
+	}
+	err := l.postForm(ctx, path, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return &MoveRejectedError{GameID: gameID, Move: move, Reason: apiErr.Message}
+	}
+	return err
+}
+
+// ErrActionNotAllowed is matched by the errors of game actions Lichess
+// refused, such as aborting a game after both players moved.
+var ErrActionNotAllowed = errors.New("lichess: action not allowed")
+
+type GameActionError struct {
+	GameID string
+	// Action is e.g. "resign" or "draw/yes"
+	Action string
+	Reason string
+}
+
+func (e *GameActionError) Error() string {
+	return fmt.Sprintf("%v: %s in game %s: %s", ErrActionNotAllowed, e.Action, e.GameID, e.Reason)
+}
+
+func (e *GameActionError) Is(target error) bool {
+	return target == ErrActionNotAllowed
+}
+
+// gameAction posts to a board endpoint answering {"ok":true}, or to its bot
+// counterpart.
+func (l *Lichess) gameAction(ctx context.Context, gameID string, action string, path string) error {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return err
+	}
+	path = l.playPath(path)
+	resp := struct {
+		OK bool `json:"ok"`
+	}{}
+	err := l.postFormJSON(ctx, path, nil, &resp)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		return &GameActionError{GameID: gameID, Action: action, Reason: apiErr.Message}
+	}
+	if err == nil && !resp.OK {
+		return &GameActionError{GameID: gameID, Action: action, Reason: "not acknowledged"}
+	}
+	return err
+}
+
+func (g *Game) Resign(ctx context.Context) error {
+	return g.client().gameAction(ctx, g.ID, "resign", fmt.Sprintf(resignGamePath, url.PathEscape(g.ID)))
+}
+
+// Abort ends the game without result, which is only allowed before both
+// players moved.
+func (g *Game) Abort(ctx context.Context) error {
+	return g.client().gameAction(ctx, g.ID, "abort", fmt.Sprintf(abortGamePath, url.PathEscape(g.ID)))
+}
+
+func (g *Game) OfferDraw(ctx context.Context) error {
+	return g.drawAction(ctx, "yes")
+}
+
+// AcceptDraw agrees to the draw the opponent offered.
+func (g *Game) AcceptDraw(ctx context.Context) error {
+	return g.drawAction(ctx, "yes")
+}
+
+func (g *Game) DeclineDraw(ctx context.Context) error {
+	return g.drawAction(ctx, "no")
+}
+
+func (g *Game) drawAction(ctx context.Context, decision string) error {
+	return g.client().gameAction(ctx, g.ID, "draw/"+decision, fmt.Sprintf(drawGamePath, url.PathEscape(g.ID), decision))
+}
+
+// OfferTakeback proposes to take back the last move, or agrees to the
+// opponent's proposal. Proposals are announced by takebackOffer board events.
+func (g *Game) OfferTakeback(ctx context.Context) error {
+	return g.HandleTakeback(ctx, true)
+}
+
+// HandleTakeback accepts or declines the takeback the opponent proposed.
+func (g *Game) HandleTakeback(ctx context.Context, accept bool) error {
+	decision := "no"
+	if accept {
+		decision = "yes"
+	}
+	return g.client().gameAction(ctx, g.ID, "takeback/"+decision, fmt.Sprintf(takebackGamePath, url.PathEscape(g.ID), decision))
+}
+
+// ClaimVictory wins the game once the opponent left it for long enough, as
+// told by the opponentGone board events.
+func (l *Lichess) ClaimVictory(ctx context.Context, gameID string) error {
+	return l.gameAction(ctx, gameID, "claim-victory", fmt.Sprintf(claimVictoryPath, url.PathEscape(gameID)))
+}
+
+func (g *Game) ClaimVictory(ctx context.Context) error {
+	return g.client().ClaimVictory(ctx, g.ID)
+}
+
+// Berserk halves the clock of the user in an arena game, for an extra point
+// if the game is won. It must be done before the first move.
+func (l *Lichess) Berserk(ctx context.Context, gameID string) error {
+	return l.gameAction(ctx, gameID, "berserk", fmt.Sprintf(berserkPath, url.PathEscape(gameID)))
+}
+
+func (g *Game) Berserk(ctx context.Context) error {
+	return g.client().Berserk(ctx, g.ID)
+}
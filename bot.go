@@ -0,0 +1,71 @@
+package lichess
+
+import (
+	"context"
+	"strings"
+)
+
+/*
+ * BOT
+ */
+
+// POST
+const botUpgradePath = "/api/bot/account/upgrade"
+
+const (
+	boardPrefix = "/api/board/"
+	botPrefix = "/api/bot/"
+)
+
+// WithBot plays through the Bot API rather than the Board API, for clients
+// authenticated as a bot account.
+func WithBot() ClientOption {
+	return func(l *Lichess) {
+		l.bot = true
+	}
+}
+
+// SetBot selects the Bot API for the game streams, moves, chat and game
+// actions, which are otherwise sent to the Board API.
+func (l *Lichess) SetBot(bot bool) {
+	l.bot = bot
+}
+
+// IsBot reports whether games are played through the Bot API.
+func (l *Lichess) IsBot() bool {
+	return l.bot
+}
+
+// UpgradeToBot turns the authenticated account into a bot account and
+// switches the client to the Bot API. The account must not have played any
+// game, and the upgrade cannot be undone.
+func (l *Lichess) UpgradeToBot(ctx context.Context) error {
+	if err := l.authorized().requireScope(ScopeBotPlay); err != nil {
+		return err
+	}
+	resp := struct {
+		OK bool `json:"ok"`
+	}{}
+	if err := l.postFormJSON(ctx, botUpgradePath, nil, &resp); err != nil {
+		return err
+	}
+	l.bot = true
+	return nil
+}
+
+// playPath returns the Bot API counterpart of a Board API path when playing
+// as a bot.
+func (l *Lichess) playPath(path string) string {
+	if l.bot && strings.HasPrefix(path, boardPrefix) {
+		return botPrefix + strings.TrimPrefix(path, boardPrefix)
+	}
+	return path
+}
+
+// playScope returns the scope needed to play through the API in use.
+func (l *Lichess) playScope() Scope {
+	if l.bot {
+		return ScopeBotPlay
+	}
+	return ScopeBoardPlay
+}
@@ -0,0 +1,280 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * BROADCASTS
+ */
+
+// GET
+const (
+	broadcastPlayersPath = "/broadcast/%s/players" // BroadcastTournamentID
+	broadcastRoundPath = "/api/broadcast/-/-/%s" // BroadcastRoundID
+	streamBroadcastRoundPath = "/api/stream/broadcast/round/%s.pgn" // BroadcastRoundID
+)
+
+// POST
+const (
+	createBroadcastPath = "/broadcast/new"
+	updateBroadcastPath = "/broadcast/%s/edit" // BroadcastTournamentID
+	createBroadcastRoundPath = "/broadcast/%s/new" // BroadcastTournamentID
+	updateBroadcastRoundPath = "/broadcast/round/%s/edit" // BroadcastRoundID
+)
+
+// BroadcastPlayer is a line of the leaderboard of a broadcast tournament,
+// aggregated over all of its rounds.
+type BroadcastPlayer struct {
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Rating int `json:"rating,omitempty"`
+	FideID int `json:"fideId,omitempty"`
+	Federation string `json:"fed,omitempty"`
+	Score float64 `json:"score"`
+	Played int `json:"played"`
+	RatingDiff int `json:"ratingDiff,omitempty"`
+	Performance int `json:"performance,omitempty"`
+}
+
+// GetBroadcastLeaderboard returns the players of a broadcast tournament with
+// their score across rounds, best first.
+func (l *Lichess) GetBroadcastLeaderboard(ctx context.Context, tournamentID string) ([]BroadcastPlayer, error) {
+	players := []BroadcastPlayer{}
+	err := l.getJSON(ctx, fmt.Sprintf(broadcastPlayersPath, url.PathEscape(tournamentID)), &players)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Score > players[j].Score
+	})
+	return players, nil
+}
+
+type BroadcastTour struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Description string `json:"description,omitempty"`
+	CreatedAt int64 `json:"createdAt"`
+	URL string `json:"url"`
+}
+
+type BroadcastRound struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	URL string `json:"url"`
+	// CreatedAt and StartsAt are in milliseconds since the epoch
+	CreatedAt int64 `json:"createdAt"`
+	StartsAt int64 `json:"startsAt,omitempty"`
+	Ongoing bool `json:"ongoing,omitempty"`
+	Finished bool `json:"finished,omitempty"`
+}
+
+// BroadcastGame is a board of a broadcast round as of the last update.
+type BroadcastGame struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	FEN string `json:"fen"`
+	Players []BroadcastGamePlayer `json:"players"`
+	LastMove string `json:"lastMove,omitempty"`
+	Check string `json:"check,omitempty"`
+	// ThinkTime is the time the side to move has been thinking, in seconds
+	ThinkTime int `json:"thinkTime,omitempty"`
+	// Status is the PGN result, "*" while the game is ongoing
+	Status string `json:"status"`
+}
+
+type BroadcastGamePlayer struct {
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Rating int `json:"rating,omitempty"`
+	FideID int `json:"fideId,omitempty"`
+	Federation string `json:"fed,omitempty"`
+	// Clock is the remaining time in centiseconds
+	Clock int64 `json:"clock,omitempty"`
+}
+
+func (g BroadcastGame) Ongoing() bool {
+	return g.Status == "" || g.Status == "*"
+}
+
+// BroadcastRoundInfo is a round of a broadcast along with its tournament
+// and games.
+type BroadcastRoundInfo struct {
+	Tour BroadcastTour `json:"tour"`
+	Round BroadcastRound `json:"round"`
+	Games []BroadcastGame `json:"games"`
+}
+
+// GetBroadcastRound returns a round with the current state of its games, e.g.
+// to show an overview before streaming the round PGN.
+func (l *Lichess) GetBroadcastRound(ctx context.Context, roundID string) (BroadcastRoundInfo, error) {
+	round := BroadcastRoundInfo{}
+	err := l.getJSON(ctx, fmt.Sprintf(broadcastRoundPath, url.PathEscape(roundID)), &round)
+	return round, err
+}
+
+// StreamBroadcastRound sends the PGN of every game of a broadcast round, then
+// the PGN of each game again whenever it is updated, until the stream ends or
+// ctx is done.
+func (l *Lichess) StreamBroadcastRound(ctx context.Context, roundID string, games chan<- string) error {
+	req, err := l.newRequest(ctx, http.MethodGet, fmt.Sprintf(streamBroadcastRoundPath, url.PathEscape(roundID)), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", string(FormatPGN))
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	r := newNDJSONReader(resp.Body, l.lineLimit())
+	game := strings.Builder{}
+	// moves is set once the movetext of the current game was reached
+	moves := false
+	emit := func() error {
+		if game.Len() == 0 {
+			return nil
+		}
+		select {
+		case games <- game.String():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		game.Reset()
+		moves = false
+		return nil
+	}
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			return emit()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		text := strings.TrimRight(string(line), "\r\n")
+		tag := strings.HasPrefix(text, "[")
+		if (text == "" || tag) && moves {
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+		switch {
+		case text == "" && game.Len() == 0:
+			continue
+		case text != "" && !tag:
+			moves = true
+		}
+		game.WriteString(text + "\n")
+	}
+}
+
+// BroadcastOptions describes a broadcast tournament to create or update,
+// Name is required.
+type BroadcastOptions struct {
+	Name string
+	// Description is a short summary, Markdown the full description
+	Description string
+	Markdown string
+	// AutoLeaderboard computes the leaderboard from the games
+	AutoLeaderboard bool
+}
+
+func (o BroadcastOptions) values() url.Values {
+	v := url.Values{}
+	v.Set("name", o.Name)
+	if o.Description != "" {
+		v.Set("description", o.Description)
+	}
+	if o.Markdown != "" {
+		v.Set("markdown", o.Markdown)
+	}
+	v.Set("autoLeaderboard", strconv.FormatBool(o.AutoLeaderboard))
+	return v
+}
+
+// CreateBroadcast creates a broadcast tournament, owned by the authenticated
+// user. Its games are added by creating rounds.
+func (l *Lichess) CreateBroadcast(ctx context.Context, opts BroadcastOptions) (BroadcastTour, error) {
+	if err := l.authorized().requireScope(ScopeStudyWrite); err != nil {
+		return BroadcastTour{}, err
+	}
+	resp := struct {
+		Tour BroadcastTour `json:"tour"`
+	}{}
+	err := l.postFormJSON(ctx, createBroadcastPath, opts.values(), &resp)
+	return resp.Tour, err
+}
+
+// UpdateBroadcast replaces the settings of a broadcast tournament, fields
+// left empty are cleared.
+func (l *Lichess) UpdateBroadcast(ctx context.Context, tourID string, opts BroadcastOptions) error {
+	if err := l.authorized().requireScope(ScopeStudyWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(updateBroadcastPath, url.PathEscape(tourID)), opts.values())
+}
+
+// BroadcastRoundOptions describes a round to create or update, Name is
+// required. Without SyncURL the games are pushed to the round by the
+// broadcaster.
+type BroadcastRoundOptions struct {
+	Name string
+	// SyncURL is polled by Lichess for the PGN of the games
+	SyncURL string
+	StartsAt time.Time
+	// Delay holds the moves back, e.g. to prevent cheating
+	Delay time.Duration
+}
+
+func (o BroadcastRoundOptions) values() url.Values {
+	v := url.Values{}
+	v.Set("name", o.Name)
+	if o.SyncURL != "" {
+		v.Set("syncUrl", o.SyncURL)
+	}
+	if !o.StartsAt.IsZero() {
+		v.Set("startsAt", strconv.FormatInt(o.StartsAt.UnixMilli(), 10))
+	}
+	if o.Delay > 0 {
+		v.Set("delay", strconv.Itoa(int(o.Delay.Seconds())))
+	}
+	return v
+}
+
+// CreateBroadcastRound adds a round to a broadcast tournament of the
+// authenticated user.
+func (l *Lichess) CreateBroadcastRound(ctx context.Context, tourID string, opts BroadcastRoundOptions) (BroadcastRound, error) {
+	if err := l.authorized().requireScope(ScopeStudyWrite); err != nil {
+		return BroadcastRound{}, err
+	}
+	resp := struct {
+		Round BroadcastRound `json:"round"`
+	}{}
+	err := l.postFormJSON(ctx, fmt.Sprintf(createBroadcastRoundPath, url.PathEscape(tourID)), opts.values(), &resp)
+	return resp.Round, err
+}
+
+// UpdateBroadcastRound replaces the settings of a round, fields left empty
+// are cleared.
+func (l *Lichess) UpdateBroadcastRound(ctx context.Context, roundID string, opts BroadcastRoundOptions) error {
+	if err := l.authorized().requireScope(ScopeStudyWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(updateBroadcastRoundPath, url.PathEscape(roundID)), opts.values())
+}
@@ -0,0 +1,167 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * BULK
+ */
+
+const defaultBulkWorkers = 4
+
+// Limiter spaces out calls so that at most one starts per interval. A single
+// Limiter may be shared by any number of goroutines and bulk runs.
+type Limiter struct {
+	interval time.Duration
+
+	mu sync.Mutex
+	next time.Time
+}
+
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// Wait blocks until the caller may proceed or ctx is done.
+func (lim *Limiter) Wait(ctx context.Context) error {
+	if lim == nil || lim.interval <= 0 {
+		return ctx.Err()
+	}
+
+	lim.mu.Lock()
+	now := time.Now()
+	at := lim.next
+	if at.Before(now) {
+		at = now
+	}
+	lim.next = at.Add(lim.interval)
+	lim.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type BulkOptions struct {
+	// Workers is the number of concurrent calls, defaults to 4
+	Workers int
+	// Limiter, if set, is waited on before every call
+	Limiter *Limiter
+	// StopOnError cancels the remaining calls after the first failure
+	StopOnError bool
+}
+
+// BulkError aggregates the failures of a bulk run, keyed by input index.
+type BulkError struct {
+	Errors map[int]error
+}
+
+func (e *BulkError) Error() string {
+	idx := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+
+	msgs := []string{}
+	for _, i := range idx {
+		msgs = append(msgs, fmt.Sprintf("#%d: %v", i, e.Errors[i]))
+		if len(msgs) == 5 && len(idx) > 5 {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(idx)-5))
+			break
+		}
+	}
+	return fmt.Sprintf("lichess: %d bulk calls failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Bulk calls fn for every input with a bounded worker pool. Results are
+// returned in input order; failed calls leave the zero value in their slot
+// and are reported together in a *BulkError.
+func Bulk[In any, Out any](ctx context.Context, inputs []In, opts BulkOptions, fn func(context.Context, In) (Out, error)) ([]Out, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Out, len(inputs))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	errs := map[int]error{}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := opts.Limiter.Wait(ctx)
+				if err == nil {
+					results[i], err = fn(ctx, inputs[i])
+				}
+				if err != nil {
+					mu.Lock()
+					errs[i] = err
+					mu.Unlock()
+					if opts.StopOnError {
+						cancel()
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range inputs {
+		if ctx.Err() != nil {
+			mu.Lock()
+			errs[i] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return results, &BulkError{errs}
+	}
+	return results, nil
+}
+
+// Chunk splits items into consecutive slices of at most size elements, e.g.
+// to respect the per-request ID limits of the bulk endpoints.
+func Chunk[T any](items []T, size int) [][]T {
+	if size <= 0 {
+		return [][]T{items}
+	}
+	chunks := [][]T{}
+	for len(items) > size {
+		chunks = append(chunks, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
@@ -0,0 +1,144 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * BULK PAIRINGS
+ */
+
+// GET
+const bulkPairingsPath = "/api/bulk-pairing"
+
+// POST
+const createBulkPairingPath = "/api/bulk-pairing"
+const startBulkClocksPath = "/api/bulk-pairing/%s/start-clocks" // BulkPairingID
+
+// DELETE
+const cancelBulkPairingPath = "/api/bulk-pairing/%s" // BulkPairingID
+
+// BulkPairing is a set of games created at once between players who gave
+// their tokens to the organizer. The dates are in milliseconds since the
+// epoch.
+type BulkPairing struct {
+	ID string `json:"id"`
+	Games []BulkPairingGame `json:"games"`
+	Variant string `json:"variant"`
+	Clock ArenaClock `json:"clock"`
+	Rated bool `json:"rated"`
+	PairAt int64 `json:"pairAt"`
+	PairedAt int64 `json:"pairedAt,omitempty"`
+	StartClocksAt int64 `json:"startClocksAt,omitempty"`
+	ScheduledAt int64 `json:"scheduledAt"`
+}
+
+// BulkPairingGame is a game of a bulk pairing, with the players by user ID.
+type BulkPairingGame struct {
+	ID string `json:"id"`
+	White string `json:"white"`
+	Black string `json:"black"`
+}
+
+// BulkPairingOptions describes the games to create, each pair holds the
+// tokens of the white and black players, with the challenge:write scope.
+type BulkPairingOptions struct {
+	Pairs [][2]string
+	// Clock is the real-time clock, Days is set instead for correspondence
+	Clock ArenaClock
+	Days int
+	// PairAt delays the creation of the games, StartClocksAt starts the
+	// clocks of the games at the given time if nobody moved yet
+	PairAt time.Time
+	StartClocksAt time.Time
+	Rated bool
+	Variant string
+	FEN string
+	// Message is sent to the players, {game} is replaced with the game URL
+	Message string
+	// Rules are e.g. "noAbort", "noRematch", "noGiveTime" or "noClaimWin"
+	Rules []string
+}
+
+func (o BulkPairingOptions) values() url.Values {
+	v := url.Values{}
+	pairs := make([]string, len(o.Pairs))
+	for i, p := range o.Pairs {
+		pairs[i] = p[0] + ":" + p[1]
+	}
+	v.Set("players", strings.Join(pairs, ","))
+	if o.Days > 0 {
+		v.Set("days", strconv.Itoa(o.Days))
+	} else {
+		v.Set("clock.limit", strconv.Itoa(o.Clock.Limit))
+		v.Set("clock.increment", strconv.Itoa(o.Clock.Increment))
+	}
+	if !o.PairAt.IsZero() {
+		v.Set("pairAt", strconv.FormatInt(o.PairAt.UnixMilli(), 10))
+	}
+	if !o.StartClocksAt.IsZero() {
+		v.Set("startClocksAt", strconv.FormatInt(o.StartClocksAt.UnixMilli(), 10))
+	}
+	v.Set("rated", strconv.FormatBool(o.Rated))
+	if o.Variant != "" {
+		v.Set("variant", o.Variant)
+	}
+	if o.FEN != "" {
+		v.Set("fen", o.FEN)
+	}
+	if o.Message != "" {
+		v.Set("message", o.Message)
+	}
+	if len(o.Rules) > 0 {
+		v.Set("rules", strings.Join(o.Rules, ","))
+	}
+	return v
+}
+
+// CreateBulkPairing schedules the games of opts. Lichess refuses the whole
+// pairing if any token is invalid or its player is already busy.
+func (l *Lichess) CreateBulkPairing(ctx context.Context, opts BulkPairingOptions) (BulkPairing, error) {
+	if err := l.authorized().requireScope(ScopeChallengeBulk); err != nil {
+		return BulkPairing{}, err
+	}
+	bulk := BulkPairing{}
+	err := l.postFormJSON(ctx, createBulkPairingPath, opts.values(), &bulk)
+	return bulk, err
+}
+
+// ListBulkPairings returns the bulk pairings created by the authenticated
+// user.
+func (l *Lichess) ListBulkPairings(ctx context.Context) ([]BulkPairing, error) {
+	if err := l.authorized().requireScope(ScopeChallengeBulk); err != nil {
+		return nil, err
+	}
+	resp := struct {
+		Bulks []BulkPairing `json:"bulks"`
+	}{}
+	err := l.getJSON(ctx, bulkPairingsPath, &resp)
+	return resp.Bulks, err
+}
+
+// StartBulkPairingClocks starts the clocks of the games of a bulk pairing
+// right away, whether or not the players moved.
+func (l *Lichess) StartBulkPairingClocks(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeChallengeBulk); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(startBulkClocksPath, url.PathEscape(id)), nil)
+}
+
+// CancelBulkPairing cancels a bulk pairing whose games are not created yet,
+// or aborts the ones that are.
+func (l *Lichess) CancelBulkPairing(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeChallengeBulk); err != nil {
+		return err
+	}
+	return l.sendJSON(ctx, http.MethodDelete, fmt.Sprintf(cancelBulkPairingPath, url.PathEscape(id)), nil, nil)
+}
@@ -0,0 +1,235 @@
+package lichess
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+)
+
+/*
+ * CACHE
+ */
+
+const (
+	defaultCacheEntries = 256
+	defaultCacheEntrySize = 1 << 20
+)
+
+// ResponseCache is an http.RoundTripper that remembers GET responses carrying
+// an ETag or Last-Modified header and revalidates them with If-None-Match /
+// If-Modified-Since. A 304 from Lichess is answered from the cache, which
+// keeps frequently refreshed resources such as profiles, teams and
+// tournaments from counting against the rate limit as full requests.
+//
+// Streaming (ndjson) responses are never cached.
+type ResponseCache struct {
+	// Transport performs the actual requests, http.DefaultTransport if nil
+	Transport http.RoundTripper
+	// MaxEntries bounds the number of cached responses, least recently used
+	// entries are evicted first
+	MaxEntries int
+	// MaxEntrySize is the largest body in bytes that will be cached
+	MaxEntrySize int64
+
+	mu sync.Mutex
+	entries map[string]*list.Element
+	lru *list.List
+}
+
+type cacheEntry struct {
+	key string
+	etag string
+	lastModified string
+	header http.Header
+	body []byte
+}
+
+func NewResponseCache(transport http.RoundTripper) *ResponseCache {
+	return &ResponseCache{
+		Transport: transport,
+		MaxEntries: defaultCacheEntries,
+		MaxEntrySize: defaultCacheEntrySize,
+		entries: map[string]*list.Element{},
+		lru: list.New(),
+	}
+}
+
+// EnableCache wraps the client's transport in a new ResponseCache and returns
+// it so it can be tuned or cleared.
+func (c *AuthorizedClient) EnableCache() *ResponseCache {
+	cache := NewResponseCache(c.Client.Transport)
+	c.Client.Transport = cache
+	return cache
+}
+
+func cacheKey(req *http.Request) string {
+	return req.URL.String() + " " + req.Header.Get("Accept")
+}
+
+func (c *ResponseCache) transport() http.RoundTripper {
+	if c.Transport == nil {
+		return http.DefaultTransport
+	}
+	return c.Transport
+}
+
+// WithResponseCache answers the GET requests of the client through cache,
+// whether authenticated or not, e.g. for dashboards refreshing profiles,
+// leaderboards or TV channels. A nil cache gets the default limits.
+func WithResponseCache(cache *ResponseCache) ClientOption {
+	return func(l *Lichess) {
+		if cache == nil {
+			cache = NewResponseCache(nil)
+		}
+		l.cache = cache
+	}
+}
+
+// Wrap returns a transport sending the requests through base, ignoring the
+// Transport of c, with the responses cached in c. It lets a single cache
+// serve clients with different transports.
+func (c *ResponseCache) Wrap(base http.RoundTripper) http.RoundTripper {
+	return cachedTransport{cache: c, base: base}
+}
+
+type cachedTransport struct {
+	cache *ResponseCache
+	base http.RoundTripper
+}
+
+func (t cachedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return t.cache.roundTrip(base, req)
+}
+
+func (c *ResponseCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.roundTrip(c.transport(), req)
+}
+
+func (c *ResponseCache) roundTrip(transport http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || isStreamRequest(req) {
+		return transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry := c.get(key)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return entry.response(req), nil
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || (etag == "" && lastModified == "") ||
+		resp.Header.Get("Content-Type") == "application/x-ndjson" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.MaxEntrySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > c.MaxEntrySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.put(&cacheEntry{
+		key: key,
+		etag: etag,
+		lastModified: lastModified,
+		header: resp.Header.Clone(),
+		body: body,
+	})
+	return resp, nil
+}
+
+func isStreamRequest(req *http.Request) bool {
+	return req.Header.Get("Accept") == "application/x-ndjson"
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status: "200 OK",
+		StatusCode: http.StatusOK,
+		Proto: "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: e.header.Clone(),
+		Body: io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request: req,
+	}
+}
+
+func (c *ResponseCache) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		return nil
+	}
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*cacheEntry)
+}
+
+func (c *ResponseCache) put(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+		c.lru = list.New()
+	}
+	if el, ok := c.entries[entry.key]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+
+	for c.MaxEntries > 0 && c.lru.Len() > c.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Clear drops every cached response.
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*list.Element{}
+	c.lru = list.New()
+}
@@ -0,0 +1,274 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * CHALLENGE
+ */
+
+// GET
+const listChallengesPath = "/api/challenge"
+
+// POST
+const challengeAIPath = "/api/challenge/ai"
+const challengeUserPath = "/api/challenge/%s" // Username
+const challengeOpenPath = "/api/challenge/open"
+
+// ChallengeOptions sets up the game of a challenge. A nil Clock with no
+// Days creates an unlimited game.
+type ChallengeOptions struct {
+	Rated bool
+	Clock *Clock
+	// Days per move of a correspondence game, used when Clock is nil
+	Days int
+	// Color is ColorRandom if empty
+	Color Color
+	Variant VariantKey
+	// FEN sets a custom initial position, for standard games only
+	FEN string
+}
+
+func (o ChallengeOptions) values() url.Values {
+	v := url.Values{}
+	if o.Rated {
+		v.Set("rated", "true")
+	}
+	if o.Clock != nil {
+		// challenge clocks are sent in seconds
+		v.Set("clock.limit", strconv.Itoa(int(o.Clock.Initial/time.Second)))
+		v.Set("clock.increment", strconv.Itoa(int(o.Clock.Increment/time.Second)))
+	} else if o.Days > 0 {
+		v.Set("days", strconv.Itoa(o.Days))
+	}
+	if o.Color != "" {
+		v.Set("color", string(o.Color))
+	}
+	if o.Variant != "" {
+		v.Set("variant", string(o.Variant))
+	}
+	if o.FEN != "" {
+		v.Set("fen", o.FEN)
+	}
+	return v
+}
+
+// ChallengeUser challenges username to a game, the challenge is then
+// announced to both players on their event streams.
+func (l *Lichess) ChallengeUser(ctx context.Context, username string, opts ChallengeOptions) (Challenge, error) {
+	if err := l.authorized().requireScope(ScopeChallengeWrite); err != nil {
+		return Challenge{}, err
+	}
+	// the challenge used to be nested under "challenge"
+	resp := struct {
+		Challenge
+		Nested *Challenge `json:"challenge"`
+	}{}
+	if err := l.postFormJSON(ctx, fmt.Sprintf(challengeUserPath, url.PathEscape(username)), opts.values(), &resp); err != nil {
+		return Challenge{}, err
+	}
+	if resp.Nested != nil {
+		return *resp.Nested, nil
+	}
+	return resp.Challenge, nil
+}
+
+// OpenChallengeOptions adds to the game settings who may join an open
+// challenge and for how long.
+type OpenChallengeOptions struct {
+	ChallengeOptions
+	// Name is shown on the challenge page
+	Name string
+	// Users restricts the challenge to two usernames, who get the two colors
+	Users []string
+	// ExpiresAt is when the challenge is deleted if nobody joined, 24 hours
+	// after its creation by default
+	ExpiresAt time.Time
+}
+
+func (o OpenChallengeOptions) values() url.Values {
+	v := o.ChallengeOptions.values()
+	if o.Name != "" {
+		v.Set("name", o.Name)
+	}
+	if len(o.Users) > 0 {
+		v.Set("users", strings.Join(o.Users, ","))
+	}
+	if !o.ExpiresAt.IsZero() {
+		v.Set("expiresAt", strconv.FormatInt(o.ExpiresAt.UnixMilli(), 10))
+	}
+	return v
+}
+
+// OpenChallenge is a challenge anyone with its link can join. The game starts
+// once both colors were claimed.
+type OpenChallenge struct {
+	Challenge
+	// URLWhite and URLBlack join the game with that color
+	URLWhite string `json:"urlWhite"`
+	URLBlack string `json:"urlBlack"`
+}
+
+// CreateOpenChallenge creates a challenge to share over chat or a stream
+// overlay, no scope is required.
+func (l *Lichess) CreateOpenChallenge(ctx context.Context, opts OpenChallengeOptions) (OpenChallenge, error) {
+	challenge := OpenChallenge{}
+	err := l.postFormJSON(ctx, challengeOpenPath, opts.values(), &challenge)
+	return challenge, err
+}
+
+// Challenges holds the pending challenges of the user.
+type Challenges struct {
+	In []Challenge `json:"in"`
+	Out []Challenge `json:"out"`
+}
+
+// GetChallenges returns the challenges received and sent by the user that
+// are still pending.
+func (l *Lichess) GetChallenges(ctx context.Context) (Challenges, error) {
+	if err := l.authorized().requireScope(ScopeChallengeRead); err != nil {
+		return Challenges{}, err
+	}
+	challenges := Challenges{}
+	err := l.getJSON(ctx, listChallengesPath, &challenges)
+	return challenges, err
+}
+
+// DeclineReason is the reason shown to the challenger, in their language.
+type DeclineReason string
+
+const (
+	DeclineGeneric DeclineReason = "generic"
+	DeclineLater DeclineReason = "later"
+	DeclineTooFast DeclineReason = "tooFast"
+	DeclineTooSlow DeclineReason = "tooSlow"
+	DeclineTimeControl DeclineReason = "timeControl"
+	DeclineRated DeclineReason = "rated"
+	DeclineCasual DeclineReason = "casual"
+	DeclineStandard DeclineReason = "standard"
+	DeclineVariant DeclineReason = "variant"
+	DeclineNoBot DeclineReason = "noBot"
+	DeclineOnlyBot DeclineReason = "onlyBot"
+)
+
+func (r DeclineReason) String() string {
+	return string(r)
+}
+
+func (r DeclineReason) Valid() bool {
+	switch r {
+	case DeclineGeneric, DeclineLater, DeclineTooFast, DeclineTooSlow,
+		DeclineTimeControl, DeclineRated, DeclineCasual, DeclineStandard,
+		DeclineVariant, DeclineNoBot, DeclineOnlyBot:
+		return true
+	}
+	return false
+}
+
+// respondChallenge posts a decision on a challenge, which the board, bot and
+// challenge scopes all allow.
+func (l *Lichess) respondChallenge(ctx context.Context, challengeID string, decision string, values url.Values) error {
+	if err := l.authorized().requireScope(ScopeChallengeWrite, ScopeBoardPlay, ScopeBotPlay); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(challengeRespPath, url.PathEscape(challengeID), decision), values)
+}
+
+// AcceptChallenge accepts a challenge received by the user, the game then
+// starts on the event stream.
+func (l *Lichess) AcceptChallenge(ctx context.Context, challengeID string) error {
+	return l.respondChallenge(ctx, challengeID, "accept", nil)
+}
+
+// DeclineChallenge declines a challenge received by the user, an empty
+// reason declines without one.
+func (l *Lichess) DeclineChallenge(ctx context.Context, challengeID string, reason DeclineReason) error {
+	if reason != "" && !reason.Valid() {
+		return fmt.Errorf("lichess: unknown decline reason %q", reason)
+	}
+	var values url.Values
+	if reason != "" {
+		values = url.Values{"reason": {string(reason)}}
+	}
+	return l.respondChallenge(ctx, challengeID, "decline", values)
+}
+
+// CancelChallenge withdraws a challenge sent by the user.
+func (l *Lichess) CancelChallenge(ctx context.Context, challengeID string) error {
+	return l.respondChallenge(ctx, challengeID, "cancel", nil)
+}
+
+// ChallengeAI starts a game against Stockfish at level 1 to 8. The game
+// starts immediately, its gameStart event follows on the event stream, and
+// it can be followed right away with NewGameSession.
+func (l *Lichess) ChallengeAI(ctx context.Context, level int, opts ChallengeOptions) (Game, error) {
+	if err := l.authorized().requireScope(ScopeChallengeWrite); err != nil {
+		return Game{}, err
+	}
+	if level < 1 || level > 8 {
+		return Game{}, fmt.Errorf("lichess: AI level %d out of range 1-8", level)
+	}
+
+	values := opts.values()
+	values.Set("level", strconv.Itoa(level))
+	// the color of the user is named "player" here
+	resp := struct {
+		Game
+		Player string `json:"player"`
+	}{}
+	if err := l.postFormJSON(ctx, challengeAIPath, values, &resp); err != nil {
+		return Game{}, err
+	}
+	game := resp.Game
+	if game.Color == "" {
+		game.Color = Color(resp.Player)
+	}
+	game.lichess = l
+	return game, nil
+}
+
+// PlayAgainstAI starts a game against Stockfish and returns a session
+// following it. A nil clock plays an unlimited game.
+func (l *Lichess) PlayAgainstAI(ctx context.Context, level int, clock *Clock) (*GameSession, error) {
+	if err := l.authorized().requireScope(ScopeBoardPlay); err != nil {
+		return nil, err
+	}
+
+	// the event stream is opened first so the gameStart cannot be missed
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan Event)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- l.StreamEvents(streamCtx, events)
+	}()
+
+	game, err := l.ChallengeAI(ctx, level, ChallengeOptions{Clock: clock})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case event := <-events:
+			if event.Type != "gameStart" || event.Game.ID != game.ID {
+				continue
+			}
+			return l.newGameSession(ctx, event.Game, true), nil
+		case err := <-streamErr:
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
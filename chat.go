@@ -0,0 +1,137 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+ * CHAT QUEUE
+ */
+
+// Lichess mutes accounts that post faster than this in game chats.
+const defaultChatInterval = 2 * time.Second
+const defaultChatPending = 16
+
+var (
+	ErrChatDropped = errors.New("lichess: chat message dropped by flood protection")
+	ErrChatClosed = errors.New("lichess: chat queue closed")
+)
+
+// ChatSender posts one chat message, e.g. Lichess.SendChat.
+type ChatSender func(ctx context.Context, gameID string, room string, text string) error
+
+type ChatPolicy int
+
+const (
+	// ChatDelay queues messages over the limit and sends them later
+	ChatDelay ChatPolicy = iota
+	// ChatDrop discards messages that cannot be sent right away
+	ChatDrop
+)
+
+type ChatQueueOptions struct {
+	// Interval is the minimum time between two messages, 2s by default
+	Interval time.Duration
+	Policy ChatPolicy
+	// MaxPending bounds the delayed messages, extra ones are dropped
+	MaxPending int
+	// OnError is called with the error of every message that failed to send
+	OnError func(gameID string, text string, err error)
+}
+
+// ChatQueue paces the messages of a chatty bot so it never trips the chat
+// flood limits. It is safe for concurrent use.
+type ChatQueue struct {
+	send ChatSender
+	opts ChatQueueOptions
+	limiter *Limiter
+
+	mu sync.Mutex
+	last time.Time
+	closed bool
+	pending chan chatMessage
+	cancel context.CancelFunc
+	done chan struct{}
+}
+
+type chatMessage struct {
+	gameID string
+	room string
+	text string
+}
+
+func NewChatQueue(send ChatSender, opts ChatQueueOptions) *ChatQueue {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultChatInterval
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = defaultChatPending
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &ChatQueue{
+		send: send,
+		opts: opts,
+		limiter: NewLimiter(opts.Interval),
+		pending: make(chan chatMessage, opts.MaxPending),
+		cancel: cancel,
+		done: make(chan struct{}),
+	}
+	go q.run(ctx)
+	return q
+}
+
+// NewChatQueue returns a ChatQueue posting through the board API.
+func (l *Lichess) NewChatQueue(opts ChatQueueOptions) *ChatQueue {
+	return NewChatQueue(l.SendChat, opts)
+}
+
+// Send queues text for the room ("player" or "spectator") of a game. It
+// returns ErrChatDropped if the policy or a full queue discards it.
+func (q *ChatQueue) Send(gameID string, room string, text string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrChatClosed
+	}
+	if q.opts.Policy == ChatDrop && (len(q.pending) > 0 || time.Since(q.last) < q.opts.Interval) {
+		return ErrChatDropped
+	}
+	select {
+	case q.pending <- chatMessage{gameID, room, text}:
+		q.last = time.Now()
+		return nil
+	default:
+		return ErrChatDropped
+	}
+}
+
+func (q *ChatQueue) run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		select {
+		case msg := <-q.pending:
+			if err := q.limiter.Wait(ctx); err != nil {
+				return
+			}
+			if err := q.send(ctx, msg.gameID, msg.room, msg.text); err != nil && q.opts.OnError != nil {
+				q.opts.OnError(msg.gameID, msg.text, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops the queue, discarding messages not sent yet.
+func (q *ChatQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cancel()
+	<-q.done
+}
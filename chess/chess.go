@@ -0,0 +1,109 @@
+// Package chess implements the minimal chess rules needed to follow Lichess
+// games locally: FEN parsing, UCI move application and SAN generation.
+package chess
+
+import "strings"
+
+type Color uint8
+
+const (
+	White Color = iota
+	Black
+)
+
+func (c Color) Other() Color {
+	return c ^ 1
+}
+
+func (c Color) String() string {
+	if c == White {
+		return "white"
+	}
+	return "black"
+}
+
+type PieceType uint8
+
+const (
+	NoPieceType PieceType = iota
+	Pawn
+	Knight
+	Bishop
+	Rook
+	Queen
+	King
+)
+
+const pieceLetters = " pnbrqk"
+
+// Letter returns the lowercase FEN letter of the piece type.
+func (t PieceType) Letter() string {
+	if t == NoPieceType || int(t) >= len(pieceLetters) {
+		return ""
+	}
+	return pieceLetters[t : t+1]
+}
+
+func pieceTypeFromLetter(c byte) PieceType {
+	i := strings.IndexByte(pieceLetters, c|0x20)
+	if i <= 0 {
+		return NoPieceType
+	}
+	return PieceType(i)
+}
+
+type Piece struct {
+	Type PieceType
+	Color Color
+}
+
+var NoPiece = Piece{}
+
+// Letter returns the FEN letter of the piece, uppercase for white.
+func (p Piece) Letter() string {
+	l := p.Type.Letter()
+	if p.Color == White {
+		return strings.ToUpper(l)
+	}
+	return l
+}
+
+// Square indexes the board from a1 (0) to h8 (63).
+type Square int8
+
+const NoSquare Square = -1
+
+func NewSquare(file, rank int) Square {
+	return Square(rank*8 + file)
+}
+
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return NoSquare, &ParseError{"square", s}
+	}
+	return NewSquare(int(s[0]-'a'), int(s[1]-'1')), nil
+}
+
+func (s Square) File() int {
+	return int(s) % 8
+}
+
+func (s Square) Rank() int {
+	return int(s) / 8
+}
+
+func (s Square) String() string {
+	if s < 0 || s > 63 {
+		return "-"
+	}
+	return string([]byte{byte('a' + s.File()), byte('1' + s.Rank())})
+}
+
+type ParseError struct {
+	What string
+	Input string
+}
+
+func (e *ParseError) Error() string {
+	return "chess: invalid " + e.What + " \"" + e.Input + "\""
+}
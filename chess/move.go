@@ -0,0 +1,222 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Move is a move in from/to form. Castling is stored as the king capturing
+// its own rook so that Chess960 castles are unambiguous. Crazyhouse drops
+// set Drop and have no From square.
+type Move struct {
+	From Square
+	To Square
+	Promotion PieceType
+	Drop PieceType
+}
+
+// ParseUCI reads a move in UCI notation such as "e2e4", "e7e8q" or the drop
+// "N@f3". The move is not checked against any position.
+func ParseUCI(s string) (Move, error) {
+	if len(s) == 4 && s[1] == '@' {
+		to, err := ParseSquare(s[2:4])
+		drop := pieceTypeFromLetter(s[0])
+		if err != nil || drop == NoPieceType || drop == King {
+			return Move{}, &ParseError{"uci move", s}
+		}
+		return Move{From: NoSquare, To: to, Drop: drop}, nil
+	}
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, &ParseError{"uci move", s}
+	}
+	from, err := ParseSquare(s[0:2])
+	if err != nil {
+		return Move{}, &ParseError{"uci move", s}
+	}
+	to, err := ParseSquare(s[2:4])
+	if err != nil {
+		return Move{}, &ParseError{"uci move", s}
+	}
+	m := Move{From: from, To: to}
+	if len(s) == 5 {
+		m.Promotion = pieceTypeFromLetter(s[4])
+		// kings are only promoted to in antichess, which legality checks
+		if m.Promotion == NoPieceType || m.Promotion == Pawn {
+			return Move{}, &ParseError{"uci move", s}
+		}
+	}
+	return m, nil
+}
+
+func (m Move) String() string {
+	if m.Drop != NoPieceType {
+		return strings.ToUpper(m.Drop.Letter()) + "@" + m.To.String()
+	}
+	return m.From.String() + m.To.String() + m.Promotion.Letter()
+}
+
+type IllegalMoveError struct {
+	Move string
+	FEN string
+}
+
+func (e *IllegalMoveError) Error() string {
+	return fmt.Sprintf("chess: illegal move %s in %s", e.Move, e.FEN)
+}
+
+// ParseMove resolves a UCI move against the legal moves of the position.
+// Castling is accepted both as king-to-destination (e1g1) and as
+// king-takes-rook (e1h1).
+func (p *Position) ParseMove(uci string) (Move, error) {
+	m, err := ParseUCI(uci)
+	if err != nil {
+		return Move{}, err
+	}
+	return p.resolve(m)
+}
+
+func (p *Position) resolve(m Move) (Move, error) {
+	legal := p.LegalMoves()
+	for _, lm := range legal {
+		if lm == m {
+			return lm, nil
+		}
+	}
+	for _, lm := range legal {
+		if p.isCastle(lm) && m.Drop == NoPieceType && lm.From == m.From && p.castleKingTarget(lm) == m.To && m.Promotion == NoPieceType {
+			return lm, nil
+		}
+	}
+	return Move{}, &IllegalMoveError{m.String(), p.FEN()}
+}
+
+// Play applies a legal move to the position.
+func (p *Position) Play(m Move) error {
+	lm, err := p.resolve(m)
+	if err != nil {
+		return err
+	}
+	p.play(lm)
+	return nil
+}
+
+// PlayUCI parses and applies a move in UCI notation.
+func (p *Position) PlayUCI(uci string) (Move, error) {
+	m, err := p.ParseMove(uci)
+	if err != nil {
+		return Move{}, err
+	}
+	p.play(m)
+	return m, nil
+}
+
+func (p *Position) isCastle(m Move) bool {
+	if m.Drop != NoPieceType {
+		return false
+	}
+	piece := p.board[m.From]
+	return piece.Type == King && p.board[m.To] == Piece{Rook, piece.Color}
+}
+
+func (p *Position) castleKingTarget(m Move) Square {
+	if m.To.File() > m.From.File() {
+		return NewSquare(6, m.From.Rank())
+	}
+	return NewSquare(2, m.From.Rank())
+}
+
+// play applies a move without checking its legality.
+func (p *Position) play(m Move) {
+	color := p.turn
+	epSquare := p.epSquare
+	p.epSquare = NoSquare
+	p.halfmoves++
+
+	switch {
+	case m.Drop != NoPieceType:
+		p.board[m.To] = Piece{m.Drop, color}
+		p.pockets[color][m.Drop]--
+	case p.isCastle(m):
+		piece := p.board[m.From]
+		rank := m.From.Rank()
+		kingTo, rookTo := NewSquare(6, rank), NewSquare(5, rank)
+		if m.To.File() < m.From.File() {
+			kingTo, rookTo = NewSquare(2, rank), NewSquare(3, rank)
+		}
+		p.board[m.From] = NoPiece
+		p.board[m.To] = NoPiece
+		p.board[kingTo] = piece
+		p.board[rookTo] = Piece{Rook, color}
+		p.castleRooks[color] = [2]Square{NoSquare, NoSquare}
+	default:
+		p.playNormal(m, epSquare)
+	}
+
+	if color == Black {
+		p.fullmoves++
+	}
+	p.turn = color.Other()
+	if p.variant == ThreeCheck && p.InCheck() {
+		p.checks[color]++
+	}
+}
+
+func (p *Position) playNormal(m Move, epSquare Square) {
+	piece := p.board[m.From]
+	captured := p.board[m.To]
+	capturedPromoted := p.promoted&(1<<uint(m.To)) != 0
+	color := piece.Color
+	capture := captured != NoPiece
+
+	if piece.Type == Pawn {
+		p.halfmoves = 0
+		if m.To == epSquare && captured == NoPiece && m.From.File() != m.To.File() {
+			p.board[NewSquare(m.To.File(), m.From.Rank())] = NoPiece
+			captured, capture = Piece{Pawn, color.Other()}, true
+		}
+		// horde pawns moving two squares from the first rank cannot be
+		// taken en passant
+		if d := m.To.Rank() - m.From.Rank(); (d == 2 || d == -2) && m.From.Rank() != 0 && m.From.Rank() != 7 {
+			p.epSquare = NewSquare(m.From.File(), (m.From.Rank()+m.To.Rank())/2)
+		}
+	}
+	if capture {
+		p.halfmoves = 0
+	}
+
+	p.board[m.From] = NoPiece
+	if m.Promotion != NoPieceType {
+		piece.Type = m.Promotion
+	}
+	p.board[m.To] = piece
+
+	if p.variant == Crazyhouse {
+		if capture {
+			t := captured.Type
+			if capturedPromoted {
+				t = Pawn
+			}
+			p.pockets[color][t]++
+		}
+		moved := p.promoted&(1<<uint(m.From)) != 0 || m.Promotion != NoPieceType
+		p.promoted &^= 1<<uint(m.From) | 1<<uint(m.To)
+		if moved {
+			p.promoted |= 1 << uint(m.To)
+		}
+	}
+
+	if piece.Type == King && p.variant != Antichess {
+		p.castleRooks[color] = [2]Square{NoSquare, NoSquare}
+	}
+	for c := range p.castleRooks {
+		for side, sq := range p.castleRooks[c] {
+			if sq == m.From || sq == m.To {
+				p.castleRooks[c][side] = NoSquare
+			}
+		}
+	}
+
+	if p.variant == Atomic && capture {
+		p.explode(m.To)
+	}
+}
@@ -0,0 +1,328 @@
+package chess
+
+var (
+	knightSteps = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	rookDirs = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopDirs = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	promotions = []PieceType{Queen, Rook, Bishop, Knight}
+)
+
+func offset(sq Square, df, dr int) Square {
+	f, r := sq.File()+df, sq.Rank()+dr
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return NoSquare
+	}
+	return NewSquare(f, r)
+}
+
+func pawnDir(c Color) int {
+	if c == White {
+		return 1
+	}
+	return -1
+}
+
+// LegalMoves returns every legal move of the side to move, under the rules
+// of the position's variant. There are none once the game is over.
+func (p *Position) LegalMoves() []Move {
+	if _, over := p.variantEnd(); over {
+		return nil
+	}
+	return p.legalMoves()
+}
+
+func (p *Position) legalMoves() []Move {
+	if p.variant == Antichess {
+		return p.antichessMoves()
+	}
+	var legal []Move
+	for _, m := range p.pseudoMoves() {
+		if p.variant == Atomic && p.board[m.From].Type == King && p.board[m.To] != NoPiece {
+			// kings cannot capture in atomic, they would explode
+			continue
+		}
+		cpy := *p
+		cpy.play(m)
+		if cpy.safe(p.turn) {
+			legal = append(legal, m)
+		}
+	}
+	for side := range p.castleRooks[p.turn] {
+		if m, ok := p.castle(side); ok {
+			legal = append(legal, m)
+		}
+	}
+	return legal
+}
+
+// safe reports whether the position, reached by a move of c, is legal for c.
+func (p *Position) safe(c Color) bool {
+	switch p.variant {
+	case Atomic:
+		// exploding the opponent's king wins even when in check, and
+		// connected kings cannot give check
+		if p.kingSquare(c) == NoSquare {
+			return false
+		}
+		if p.kingSquare(c.Other()) == NoSquare || p.kingsAdjacent() {
+			return true
+		}
+	case RacingKings:
+		// giving check is not allowed either
+		return !p.isKingAttacked(White) && !p.isKingAttacked(Black)
+	}
+	return !p.isKingAttacked(c)
+}
+
+// antichessMoves returns the moves of antichess, where kings are ordinary
+// pieces and capturing is compulsory.
+func (p *Position) antichessMoves() []Move {
+	moves := p.pseudoMoves()
+	var captures []Move
+	for _, m := range moves {
+		if p.board[m.To] != NoPiece || (m.To == p.epSquare && p.board[m.From].Type == Pawn && m.From.File() != m.To.File()) {
+			captures = append(captures, m)
+		}
+	}
+	if len(captures) > 0 {
+		return captures
+	}
+	return moves
+}
+
+func (p *Position) pseudoMoves() []Move {
+	var moves []Move
+	us := p.turn
+	for i, piece := range p.board {
+		from := Square(i)
+		if piece == NoPiece || piece.Color != us {
+			continue
+		}
+		switch piece.Type {
+		case Pawn:
+			moves = p.pawnMoves(moves, from)
+		case Knight:
+			moves = p.stepMoves(moves, from, knightSteps)
+		case King:
+			moves = p.stepMoves(moves, from, kingSteps)
+		case Bishop:
+			moves = p.slideMoves(moves, from, bishopDirs)
+		case Rook:
+			moves = p.slideMoves(moves, from, rookDirs)
+		case Queen:
+			moves = p.slideMoves(moves, from, bishopDirs)
+			moves = p.slideMoves(moves, from, rookDirs)
+		}
+	}
+	if p.variant == Crazyhouse {
+		moves = p.dropMoves(moves)
+	}
+	return moves
+}
+
+// dropMoves adds the crazyhouse drops of the pieces in hand, pawns cannot be
+// dropped on the first and last ranks.
+func (p *Position) dropMoves(moves []Move) []Move {
+	for t := Pawn; t < King; t++ {
+		if p.pockets[p.turn][t] == 0 {
+			continue
+		}
+		for i, piece := range p.board {
+			to := Square(i)
+			if piece != NoPiece || (t == Pawn && (to.Rank() == 0 || to.Rank() == 7)) {
+				continue
+			}
+			moves = append(moves, Move{From: NoSquare, To: to, Drop: t})
+		}
+	}
+	return moves
+}
+
+func (p *Position) pawnMoves(moves []Move, from Square) []Move {
+	us := p.board[from].Color
+	dir := pawnDir(us)
+	lastRank := 7
+	startRank := 1
+	if us == Black {
+		lastRank, startRank = 0, 6
+	}
+
+	add := func(to Square) {
+		if to.Rank() == lastRank {
+			for _, t := range promotions {
+				moves = append(moves, Move{From: from, To: to, Promotion: t})
+			}
+			if p.variant == Antichess {
+				moves = append(moves, Move{From: from, To: to, Promotion: King})
+			}
+			return
+		}
+		moves = append(moves, Move{From: from, To: to})
+	}
+
+	// horde pawns may also move two squares from the first rank
+	double := from.Rank() == startRank || (p.variant == Horde && us == White && from.Rank() == 0)
+	if to := offset(from, 0, dir); to != NoSquare && p.board[to] == NoPiece {
+		add(to)
+		if double {
+			if to2 := offset(to, 0, dir); to2 != NoSquare && p.board[to2] == NoPiece {
+				add(to2)
+			}
+		}
+	}
+	for _, df := range []int{-1, 1} {
+		to := offset(from, df, dir)
+		if to == NoSquare {
+			continue
+		}
+		target := p.board[to]
+		if (target != NoPiece && target.Color != us) || (to == p.epSquare && target == NoPiece) {
+			add(to)
+		}
+	}
+	return moves
+}
+
+func (p *Position) stepMoves(moves []Move, from Square, steps [][2]int) []Move {
+	us := p.board[from].Color
+	for _, s := range steps {
+		to := offset(from, s[0], s[1])
+		if to == NoSquare {
+			continue
+		}
+		if target := p.board[to]; target == NoPiece || target.Color != us {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+	return moves
+}
+
+func (p *Position) slideMoves(moves []Move, from Square, dirs [][2]int) []Move {
+	us := p.board[from].Color
+	for _, d := range dirs {
+		for to := offset(from, d[0], d[1]); to != NoSquare; to = offset(to, d[0], d[1]) {
+			target := p.board[to]
+			if target == NoPiece {
+				moves = append(moves, Move{From: from, To: to})
+				continue
+			}
+			if target.Color != us {
+				moves = append(moves, Move{From: from, To: to})
+			}
+			break
+		}
+	}
+	return moves
+}
+
+// castle returns the castling move towards side if it is currently legal.
+func (p *Position) castle(side int) (Move, bool) {
+	us := p.turn
+	rook := p.castleRooks[us][side]
+	king := p.kingSquare(us)
+	if rook == NoSquare || king == NoSquare || p.board[rook] != (Piece{Rook, us}) {
+		return Move{}, false
+	}
+	rank := king.Rank()
+	kingTo, rookTo := 6, 5
+	if side == queenSide {
+		kingTo, rookTo = 2, 3
+	}
+
+	lo, hi := king.File(), king.File()
+	for _, f := range []int{rook.File(), kingTo, rookTo} {
+		if f < lo {
+			lo = f
+		}
+		if f > hi {
+			hi = f
+		}
+	}
+	for f := lo; f <= hi; f++ {
+		sq := NewSquare(f, rank)
+		if sq != king && sq != rook && p.board[sq] != NoPiece {
+			return Move{}, false
+		}
+	}
+
+	// the king may not castle out of, through or into check
+	step := 1
+	if kingTo < king.File() {
+		step = -1
+	}
+	for f := king.File(); ; f += step {
+		if p.isAttacked(NewSquare(f, rank), us.Other()) {
+			return Move{}, false
+		}
+		if f == kingTo {
+			break
+		}
+	}
+
+	m := Move{From: king, To: rook}
+	after := *p
+	after.play(m)
+	if !after.safe(us) {
+		return Move{}, false
+	}
+	return m, true
+}
+
+func (p *Position) isKingAttacked(c Color) bool {
+	king := p.kingSquare(c)
+	return king != NoSquare && p.isAttacked(king, c.Other())
+}
+
+// isAttacked reports whether any piece of color by attacks sq.
+func (p *Position) isAttacked(sq Square, by Color) bool {
+	for _, df := range []int{-1, 1} {
+		if from := offset(sq, df, -pawnDir(by)); from != NoSquare && p.board[from] == (Piece{Pawn, by}) {
+			return true
+		}
+	}
+	for _, s := range knightSteps {
+		if from := offset(sq, s[0], s[1]); from != NoSquare && p.board[from] == (Piece{Knight, by}) {
+			return true
+		}
+	}
+	for _, s := range kingSteps {
+		if from := offset(sq, s[0], s[1]); from != NoSquare && p.board[from] == (Piece{King, by}) {
+			return true
+		}
+	}
+	if p.rayAttacked(sq, by, rookDirs, Rook) || p.rayAttacked(sq, by, bishopDirs, Bishop) {
+		return true
+	}
+	return false
+}
+
+func (p *Position) rayAttacked(sq Square, by Color, dirs [][2]int, slider PieceType) bool {
+	for _, d := range dirs {
+		for from := offset(sq, d[0], d[1]); from != NoSquare; from = offset(from, d[0], d[1]) {
+			piece := p.board[from]
+			if piece == NoPiece {
+				continue
+			}
+			if piece.Color == by && (piece.Type == slider || piece.Type == Queen) {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// InCheck reports whether the side to move is in check. There is no check in
+// antichess, nor in atomic while the kings are connected.
+func (p *Position) InCheck() bool {
+	switch p.variant {
+	case Antichess:
+		return false
+	case Atomic:
+		if p.kingsAdjacent() {
+			return false
+		}
+	}
+	return p.isKingAttacked(p.turn)
+}
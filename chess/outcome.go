@@ -0,0 +1,76 @@
+package chess
+
+// IsCheckmate reports whether the side to move is checkmated.
+func (p *Position) IsCheckmate() bool {
+	return p.InCheck() && len(p.LegalMoves()) == 0
+}
+
+// IsStalemate reports whether the side to move has no legal move while not
+// being in check.
+func (p *Position) IsStalemate() bool {
+	return !p.InCheck() && len(p.LegalMoves()) == 0
+}
+
+// InsufficientMaterial reports whether neither side can possibly checkmate:
+// bare kings, a single minor piece, or only bishops all on squares of the
+// same color.
+func (p *Position) InsufficientMaterial() bool {
+	knights, bishops := 0, 0
+	bishopColors := [2]bool{}
+	for i, piece := range p.board {
+		switch piece.Type {
+		case NoPieceType, King:
+		case Knight:
+			knights++
+		case Bishop:
+			bishops++
+			sq := Square(i)
+			bishopColors[(sq.File()+sq.Rank())%2] = true
+		default:
+			return false
+		}
+	}
+	if knights+bishops <= 1 {
+		return true
+	}
+	return knights == 0 && !(bishopColors[0] && bishopColors[1])
+}
+
+// Game statuses, named after the Lichess ones
+const (
+	StatusMate = "mate"
+	StatusStalemate = "stalemate"
+	StatusDraw = "draw"
+	StatusVariantEnd = "variantEnd"
+)
+
+// Outcome is the result of a finished game. Status is empty while the game
+// goes on.
+type Outcome struct {
+	Status string
+	Winner Color
+	Draw bool
+}
+
+// Outcome tells whether the game is over by the rules of its variant: mate,
+// stalemate, insufficient material or a variant winning condition. Draws by
+// repetition or the fifty-move rule have to be claimed and are not reported.
+func (p *Position) Outcome() Outcome {
+	if o, over := p.variantEnd(); over {
+		return o
+	}
+	if len(p.legalMoves()) > 0 {
+		if p.variant.standardRules() && p.InsufficientMaterial() {
+			return Outcome{Status: StatusDraw, Draw: true}
+		}
+		return Outcome{}
+	}
+	switch {
+	case p.variant == Antichess:
+		// losing all pieces or being stalemated wins
+		return Outcome{Status: StatusVariantEnd, Winner: p.turn}
+	case p.InCheck():
+		return Outcome{Status: StatusMate, Winner: p.turn.Other()}
+	}
+	return Outcome{Status: StatusStalemate, Draw: true}
+}
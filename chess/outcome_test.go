@@ -0,0 +1,40 @@
+package chess
+
+import "testing"
+
+var outcomeTests = []struct {
+	variant Variant
+	fen string
+	want Outcome
+}{
+	{Standard, StartFEN, Outcome{}},
+	// fool's mate
+	{Standard, "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", Outcome{Status: StatusMate, Winner: Black}},
+	{Standard, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Outcome{Status: StatusStalemate, Draw: true}},
+	{Standard, "8/8/4k3/8/8/2B5/4K3/8 w - - 0 1", Outcome{Status: StatusDraw, Draw: true}},
+	// bishops on squares of the same color
+	{Standard, "8/8/4k3/8/3b4/2B5/4K3/8 w - - 0 1", Outcome{Status: StatusDraw, Draw: true}},
+	{Standard, "8/8/4k3/8/4b3/2B5/4K3/8 w - - 0 1", Outcome{}},
+	{Standard, "8/8/4k3/8/4n3/2N5/4K3/8 w - - 0 1", Outcome{}},
+	{KingOfTheHill, "rnbq1bnr/pppppppp/8/3k4/8/8/PPPPPPPP/RNBQKBNR w KQ - 0 1", Outcome{Status: StatusVariantEnd, Winner: Black}},
+	{ThreeCheck, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0+3 0 1", Outcome{Status: StatusVariantEnd, Winner: White}},
+	// the black king exploded
+	{Atomic, "rnbq1bnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQ - 0 1", Outcome{Status: StatusVariantEnd, Winner: White}},
+	{Antichess, "8/8/8/8/8/8/8/7p w - - 0 1", Outcome{Status: StatusVariantEnd, Winner: White}},
+	{RacingKings, "K7/8/8/8/8/8/8/k7 w - - 0 1", Outcome{Status: StatusVariantEnd, Winner: White}},
+	// black may still reach the last rank to draw
+	{RacingKings, "K7/6k1/8/8/8/8/8/8 b - - 0 1", Outcome{}},
+	{Horde, "4k3/8/8/8/8/8/8/8 w - - 0 1", Outcome{Status: StatusVariantEnd, Winner: Black}},
+}
+
+func TestOutcome(t *testing.T) {
+	for _, test := range outcomeTests {
+		p, err := ParseVariantFEN(test.variant, test.fen)
+		if err != nil {
+			t.Fatalf("%s %s: %v", test.variant, test.fen, err)
+		}
+		if got := p.Outcome(); got != test.want {
+			t.Errorf("%s %s: Outcome() = %+v, want %+v", test.variant, test.fen, got, test.want)
+		}
+	}
+}
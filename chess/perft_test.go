@@ -0,0 +1,58 @@
+package chess
+
+import "testing"
+
+// perft counts the leaf nodes of the move tree of p to depth.
+func perft(p *Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for _, m := range p.LegalMoves() {
+		next := p.Copy()
+		next.play(m)
+		nodes += perft(next, depth-1)
+	}
+	return nodes
+}
+
+// The counts are the published ones of each position, an empty FEN being
+// the starting position of the variant.
+var perftTests = []struct {
+	variant Variant
+	fen string
+	depth int
+	nodes int
+}{
+	{Standard, "", 4, 197281},
+	// Kiwipete, for castling, en passant and promotions
+	{Standard, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 3, 97862},
+	{Standard, "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 5, 674624},
+	{Standard, "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 4, 422333},
+	{Chess960, "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", 3, 12189},
+	{Chess960, "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", 3, 18002},
+	{Crazyhouse, "", 4, 197281},
+	{Crazyhouse, "2k5/8/8/8/8/8/8/4K3[QRBNPqrbnp] w - - 0 1", 2, 75353},
+	{Atomic, "", 4, 197326},
+	{Antichess, "", 4, 153299},
+	{KingOfTheHill, "", 4, 197281},
+	{ThreeCheck, "", 4, 197281},
+	{Horde, "", 5, 265223},
+	{RacingKings, "", 4, 296242},
+}
+
+func TestPerft(t *testing.T) {
+	for _, test := range perftTests {
+		fen := test.fen
+		if fen == "" {
+			fen = test.variant.StartFEN()
+		}
+		p, err := ParseVariantFEN(test.variant, fen)
+		if err != nil {
+			t.Fatalf("%s %s: %v", test.variant, fen, err)
+		}
+		if nodes := perft(p, test.depth); nodes != test.nodes {
+			t.Errorf("%s %s: perft(%d) = %d, want %d", test.variant, fen, test.depth, nodes, test.nodes)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package chess
+
+import (
+	"strconv"
+	"strings"
+)
+
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+const (
+	kingSide = iota
+	queenSide
+)
+
+// Position is a complete game state. The zero value is an empty board, use
+// NewPosition or ParseFEN to obtain a playable one.
+type Position struct {
+	board [64]Piece
+	turn Color
+	// castleRooks holds the square of the rook each side may still castle
+	// with, which also covers Chess960 starting positions.
+	castleRooks [2][2]Square
+	epSquare Square
+	halfmoves int
+	fullmoves int
+
+	variant Variant
+	// crazyhouse pieces in hand, indexed by piece type, and the squares of
+	// promoted pieces, which go back to the pocket as pawns
+	pockets [2][King]uint8
+	promoted uint64
+	// three-check checks given by each color
+	checks [2]uint8
+}
+
+func NewPosition() *Position {
+	p, _ := ParseFEN(StartFEN)
+	return p
+}
+
+// ParseFEN reads a position in FEN, accepting both KQkq and Shredder-FEN
+// castling fields. The move counters may be omitted.
+func ParseFEN(fen string) (*Position, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, &ParseError{"fen", fen}
+	}
+
+	p := &Position{epSquare: NoSquare, fullmoves: 1}
+	for c := range p.castleRooks {
+		p.castleRooks[c] = [2]Square{NoSquare, NoSquare}
+	}
+
+	board, pocket := fields[0], ""
+	if i := strings.IndexByte(board, '['); i >= 0 && strings.HasSuffix(board, "]") {
+		board, pocket = board[:i], board[i+1:len(board)-1]
+	}
+	ranks := strings.Split(board, "/")
+	if len(ranks) == 9 {
+		pocket = ranks[8]
+		ranks = ranks[:8]
+	}
+	if len(ranks) != 8 {
+		return nil, &ParseError{"fen", fen}
+	}
+	for i, row := range ranks {
+		rank := 7 - i
+		file := 0
+		for j := 0; j < len(row); j++ {
+			c := row[j]
+			if c >= '1' && c <= '8' {
+				file += int(c - '0')
+				continue
+			}
+			if c == '~' && file > 0 {
+				p.promoted |= 1 << uint(NewSquare(file-1, rank))
+				continue
+			}
+			t := pieceTypeFromLetter(c)
+			if t == NoPieceType || file > 7 {
+				return nil, &ParseError{"fen", fen}
+			}
+			color := White
+			if c >= 'a' {
+				color = Black
+			}
+			p.board[NewSquare(file, rank)] = Piece{t, color}
+			file++
+		}
+		if file != 8 {
+			return nil, &ParseError{"fen", fen}
+		}
+	}
+	for i := 0; i < len(pocket); i++ {
+		t := pieceTypeFromLetter(pocket[i])
+		if t == NoPieceType || t == King {
+			return nil, &ParseError{"fen", fen}
+		}
+		color := White
+		if pocket[i] >= 'a' {
+			color = Black
+		}
+		p.pockets[color][t]++
+	}
+
+	switch fields[1] {
+	case "w":
+		p.turn = White
+	case "b":
+		p.turn = Black
+	default:
+		return nil, &ParseError{"fen", fen}
+	}
+
+	if fields[2] != "-" {
+		for i := 0; i < len(fields[2]); i++ {
+			if !p.addCastlingRight(fields[2][i]) {
+				return nil, &ParseError{"fen", fen}
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		sq, err := ParseSquare(fields[3])
+		if err != nil {
+			return nil, &ParseError{"fen", fen}
+		}
+		p.epSquare = sq
+	}
+
+	// three-check counters come either as remaining checks before the move
+	// counters ("3+3") or as checks given after them ("+0+0")
+	counters := fields[4:]
+	if len(counters) > 0 && strings.Contains(counters[0], "+") {
+		if !p.parseChecks(counters[0], true) {
+			return nil, &ParseError{"fen", fen}
+		}
+		counters = counters[1:]
+	}
+	if n := len(counters); n > 0 && strings.HasPrefix(counters[n-1], "+") {
+		if !p.parseChecks(counters[n-1][1:], false) {
+			return nil, &ParseError{"fen", fen}
+		}
+		counters = counters[:n-1]
+	}
+	if len(counters) >= 2 {
+		var err error
+		if p.halfmoves, err = strconv.Atoi(counters[0]); err != nil {
+			return nil, &ParseError{"fen", fen}
+		}
+		if p.fullmoves, err = strconv.Atoi(counters[1]); err != nil {
+			return nil, &ParseError{"fen", fen}
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Position) parseChecks(s string, remaining bool) bool {
+	white, black, ok := strings.Cut(s, "+")
+	if !ok {
+		return false
+	}
+	for c, field := range []string{white, black} {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 || n > 3 {
+			return false
+		}
+		if remaining {
+			n = 3 - n
+		}
+		p.checks[c] = uint8(n)
+	}
+	return true
+}
+
+func (p *Position) addCastlingRight(c byte) bool {
+	color := White
+	if c >= 'a' {
+		color = Black
+	}
+	king := p.kingSquare(color)
+	if king == NoSquare {
+		return false
+	}
+	rank := king.Rank()
+	rook := Piece{Rook, color}
+
+	switch c | 0x20 {
+	case 'k':
+		for f := 7; f > king.File(); f-- {
+			if p.board[NewSquare(f, rank)] == rook {
+				p.castleRooks[color][kingSide] = NewSquare(f, rank)
+				return true
+			}
+		}
+	case 'q':
+		for f := 0; f < king.File(); f++ {
+			if p.board[NewSquare(f, rank)] == rook {
+				p.castleRooks[color][queenSide] = NewSquare(f, rank)
+				return true
+			}
+		}
+	default:
+		f := int(c|0x20) - 'a'
+		if f < 0 || f > 7 || p.board[NewSquare(f, rank)] != rook {
+			return false
+		}
+		side := queenSide
+		if f > king.File() {
+			side = kingSide
+		}
+		p.castleRooks[color][side] = NewSquare(f, rank)
+		return true
+	}
+	return false
+}
+
+// FEN returns the position in FEN, using Shredder-FEN castling letters only
+// when a castling rook is not in its standard corner.
+func (p *Position) FEN() string {
+	var b strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := p.board[NewSquare(file, rank)]
+			if piece == NoPiece {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteString(piece.Letter())
+			if p.variant == Crazyhouse && p.promoted&(1<<uint(NewSquare(file, rank))) != 0 {
+				b.WriteByte('~')
+			}
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+		if rank > 0 {
+			b.WriteByte('/')
+		}
+	}
+
+	if p.variant == Crazyhouse {
+		b.WriteByte('[')
+		for _, color := range []Color{White, Black} {
+			for t := Queen; t >= Pawn; t-- {
+				for n := p.pockets[color][t]; n > 0; n-- {
+					b.WriteString(Piece{t, color}.Letter())
+				}
+			}
+		}
+		b.WriteByte(']')
+	}
+
+	if p.turn == White {
+		b.WriteString(" w ")
+	} else {
+		b.WriteString(" b ")
+	}
+
+	castling := ""
+	for _, color := range []Color{White, Black} {
+		for side, std := range []int{7, 0} {
+			sq := p.castleRooks[color][side]
+			if sq == NoSquare {
+				continue
+			}
+			var c byte
+			if sq.File() == std {
+				c = "kq"[side]
+			} else {
+				c = byte('a' + sq.File())
+			}
+			if color == White {
+				c -= 0x20
+			}
+			castling += string(c)
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	b.WriteString(castling)
+
+	b.WriteByte(' ')
+	if p.epSquare != NoSquare && p.hasEnPassantCapture() {
+		b.WriteString(p.epSquare.String())
+	} else {
+		b.WriteByte('-')
+	}
+
+	if p.variant == ThreeCheck {
+		b.WriteString(" " + strconv.Itoa(p.RemainingChecks(White)) + "+" + strconv.Itoa(p.RemainingChecks(Black)))
+	}
+	b.WriteString(" " + strconv.Itoa(p.halfmoves) + " " + strconv.Itoa(p.fullmoves))
+	return b.String()
+}
+
+func (p *Position) Turn() Color {
+	return p.turn
+}
+
+// MoveNumber returns the fullmove number, starting at 1 and incremented
+// after each black move.
+func (p *Position) MoveNumber() int {
+	return p.fullmoves
+}
+
+func (p *Position) HalfmoveClock() int {
+	return p.halfmoves
+}
+
+func (p *Position) PieceAt(sq Square) Piece {
+	if sq < 0 || sq > 63 {
+		return NoPiece
+	}
+	return p.board[sq]
+}
+
+func (p *Position) Copy() *Position {
+	cpy := *p
+	return &cpy
+}
+
+func (p *Position) kingSquare(color Color) Square {
+	king := Piece{King, color}
+	for sq, piece := range p.board {
+		if piece == king {
+			return Square(sq)
+		}
+	}
+	return NoSquare
+}
+
+// hasEnPassantCapture reports whether a pawn can actually capture on the en
+// passant square, which is when FEN and PGN consider it set.
+func (p *Position) hasEnPassantCapture() bool {
+	for _, m := range p.LegalMoves() {
+		if m.Drop == NoPieceType && m.To == p.epSquare && p.board[m.From].Type == Pawn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package chess
+
+import "strings"
+
+// SAN returns the move in Standard Algebraic Notation, including check and
+// mate suffixes. The move must be legal in the position.
+func (p *Position) SAN(m Move) (string, error) {
+	lm, err := p.resolve(m)
+	if err != nil {
+		return "", err
+	}
+	legal := p.LegalMoves()
+	san := p.san(lm, legal)
+
+	after := *p
+	after.play(lm)
+	if o := after.Outcome(); o.Status == StatusMate {
+		san += "#"
+	} else if after.InCheck() {
+		san += "+"
+	}
+	return san, nil
+}
+
+func (p *Position) san(m Move, legal []Move) string {
+	if m.Drop != NoPieceType {
+		return strings.ToUpper(m.Drop.Letter()) + "@" + m.To.String()
+	}
+	piece := p.board[m.From]
+	if p.isCastle(m) {
+		if m.To.File() > m.From.File() {
+			return "O-O"
+		}
+		return "O-O-O"
+	}
+
+	capture := p.board[m.To] != NoPiece
+	var b strings.Builder
+	if piece.Type == Pawn {
+		if m.From.File() != m.To.File() {
+			capture = true
+			b.WriteByte(byte('a' + m.From.File()))
+		}
+	} else {
+		b.WriteString(strings.ToUpper(piece.Type.Letter()))
+
+		var sameFile, sameRank, ambiguous bool
+		for _, other := range legal {
+			if other.To != m.To || other.Drop != NoPieceType || other.From == m.From || p.board[other.From].Type != piece.Type || p.isCastle(other) {
+				continue
+			}
+			ambiguous = true
+			sameFile = sameFile || other.From.File() == m.From.File()
+			sameRank = sameRank || other.From.Rank() == m.From.Rank()
+		}
+		if ambiguous {
+			if !sameFile {
+				b.WriteByte(byte('a' + m.From.File()))
+			} else if !sameRank {
+				b.WriteByte(byte('1' + m.From.Rank()))
+			} else {
+				b.WriteString(m.From.String())
+			}
+		}
+	}
+
+	if capture {
+		b.WriteByte('x')
+	}
+	b.WriteString(m.To.String())
+	if m.Promotion != NoPieceType {
+		b.WriteString("=" + strings.ToUpper(m.Promotion.Letter()))
+	}
+	return b.String()
+}
+
+// ParseSAN resolves a move in Standard Algebraic Notation. Check, mate and
+// annotation suffixes are ignored.
+func (p *Position) ParseSAN(s string) (Move, error) {
+	want := strings.TrimRight(s, "+#!?")
+	want = strings.ReplaceAll(want, "0", "O")
+	if strings.HasPrefix(want, "@") {
+		// pawn drops are also written without the piece letter
+		want = "P" + want
+	}
+	legal := p.LegalMoves()
+	for _, m := range legal {
+		if p.san(m, legal) == want {
+			return m, nil
+		}
+	}
+	return Move{}, &IllegalMoveError{s, p.FEN()}
+}
+
+// PlaySAN parses and applies a move in Standard Algebraic Notation.
+func (p *Position) PlaySAN(s string) (Move, error) {
+	m, err := p.ParseSAN(s)
+	if err != nil {
+		return Move{}, err
+	}
+	p.play(m)
+	return m, nil
+}
@@ -0,0 +1,220 @@
+package chess
+
+// Variant selects the rules a position is played under, named after the
+// Lichess variant keys.
+type Variant uint8
+
+const (
+	Standard Variant = iota
+	Chess960
+	FromPosition
+	Crazyhouse
+	Atomic
+	Antichess
+	KingOfTheHill
+	ThreeCheck
+	Horde
+	RacingKings
+)
+
+var variantKeys = [...]string{
+	Standard: "standard",
+	Chess960: "chess960",
+	FromPosition: "fromPosition",
+	Crazyhouse: "crazyhouse",
+	Atomic: "atomic",
+	Antichess: "antichess",
+	KingOfTheHill: "kingOfTheHill",
+	ThreeCheck: "threeCheck",
+	Horde: "horde",
+	RacingKings: "racingKings",
+}
+
+const (
+	HordeFEN = "rnbqkbnr/pppppppp/8/1PP2PP1/PPPPPPPP/PPPPPPPP/PPPPPPPP/PPPPPPPP w kq - 0 1"
+	RacingKingsFEN = "8/8/8/8/8/8/krbnNBRK/qrbnNBRQ w - - 0 1"
+)
+
+// ParseVariant returns the variant of a Lichess variant key, an empty key
+// is standard chess.
+func ParseVariant(key string) (Variant, error) {
+	if key == "" {
+		return Standard, nil
+	}
+	for v, k := range variantKeys {
+		if k == key {
+			return Variant(v), nil
+		}
+	}
+	return Standard, &ParseError{"variant", key}
+}
+
+func (v Variant) String() string {
+	if int(v) >= len(variantKeys) {
+		return ""
+	}
+	return variantKeys[v]
+}
+
+// StartFEN returns the usual initial position of the variant. Chess960
+// positions are drawn at random and have to be given explicitly.
+func (v Variant) StartFEN() string {
+	switch v {
+	case Horde:
+		return HordeFEN
+	case RacingKings:
+		return RacingKingsFEN
+	case Crazyhouse:
+		return "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1"
+	case ThreeCheck:
+		return "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 3+3 0 1"
+	case Antichess:
+		return "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"
+	}
+	return StartFEN
+}
+
+// standardRules reports whether the variant only differs from chess by its
+// starting position.
+func (v Variant) standardRules() bool {
+	return v == Standard || v == Chess960 || v == FromPosition
+}
+
+// NewVariantPosition returns the initial position of the variant.
+func NewVariantPosition(v Variant) *Position {
+	p, _ := ParseVariantFEN(v, v.StartFEN())
+	return p
+}
+
+// ParseVariantFEN reads a position played under the rules of v. Crazyhouse
+// pockets ([Qp] or a ninth rank) and promoted pieces (Q~), as well as
+// three-check counters in either "3+3" or "+0+0" form, are understood.
+func ParseVariantFEN(v Variant, fen string) (*Position, error) {
+	p, err := ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	p.variant = v
+	if v == Antichess || v == RacingKings {
+		p.castleRooks = [2][2]Square{{NoSquare, NoSquare}, {NoSquare, NoSquare}}
+	}
+	return p, nil
+}
+
+func (p *Position) Variant() Variant {
+	return p.variant
+}
+
+// Pocket returns how many pieces of type t color holds in hand, in
+// crazyhouse.
+func (p *Position) Pocket(c Color, t PieceType) int {
+	if t >= King {
+		return 0
+	}
+	return int(p.pockets[c][t])
+}
+
+// RemainingChecks returns how many more checks c has to give to win a
+// three-check game.
+func (p *Position) RemainingChecks(c Color) int {
+	return 3 - int(p.checks[c])
+}
+
+var hill = [4]Square{27, 28, 35, 36} // d4, e4, d5, e5
+
+func onHill(sq Square) bool {
+	for _, h := range hill {
+		if sq == h {
+			return true
+		}
+	}
+	return false
+}
+
+// explode removes the pieces caught in an atomic capture on sq: the capturing
+// piece and every piece but pawns around it.
+func (p *Position) explode(sq Square) {
+	p.board[sq] = NoPiece
+	for _, s := range kingSteps {
+		if around := offset(sq, s[0], s[1]); around != NoSquare && p.board[around].Type != Pawn {
+			p.board[around] = NoPiece
+		}
+	}
+	for c := range p.castleRooks {
+		for side, rook := range p.castleRooks[c] {
+			if rook != NoSquare && p.board[rook] != (Piece{Rook, Color(c)}) {
+				p.castleRooks[c][side] = NoSquare
+			}
+		}
+		if p.kingSquare(Color(c)) == NoSquare {
+			p.castleRooks[c] = [2]Square{NoSquare, NoSquare}
+		}
+	}
+}
+
+func (p *Position) kingsAdjacent() bool {
+	wk, bk := p.kingSquare(White), p.kingSquare(Black)
+	if wk == NoSquare || bk == NoSquare {
+		return false
+	}
+	df, dr := wk.File()-bk.File(), wk.Rank()-bk.Rank()
+	return df >= -1 && df <= 1 && dr >= -1 && dr <= 1
+}
+
+func (p *Position) hasPieces(c Color) bool {
+	for _, piece := range p.board {
+		if piece != NoPiece && piece.Color == c {
+			return true
+		}
+	}
+	return false
+}
+
+// variantEnd returns the outcome of a game ended by a rule of its variant,
+// rather than by the side to move running out of moves.
+func (p *Position) variantEnd() (Outcome, bool) {
+	switch p.variant {
+	case KingOfTheHill:
+		for _, c := range []Color{White, Black} {
+			if k := p.kingSquare(c); k != NoSquare && onHill(k) {
+				return Outcome{Status: StatusVariantEnd, Winner: c}, true
+			}
+		}
+	case ThreeCheck:
+		for _, c := range []Color{White, Black} {
+			if p.checks[c] >= 3 {
+				return Outcome{Status: StatusVariantEnd, Winner: c}, true
+			}
+		}
+	case Atomic:
+		for _, c := range []Color{White, Black} {
+			if p.kingSquare(c) == NoSquare {
+				return Outcome{Status: StatusVariantEnd, Winner: c.Other()}, true
+			}
+		}
+	case Horde:
+		if !p.hasPieces(White) {
+			return Outcome{Status: StatusVariantEnd, Winner: Black}, true
+		}
+	case RacingKings:
+		white := p.kingSquare(White).Rank() == 7
+		black := p.kingSquare(Black).Rank() == 7
+		switch {
+		case white && black:
+			return Outcome{Status: StatusDraw, Draw: true}, true
+		case black:
+			return Outcome{Status: StatusVariantEnd, Winner: Black}, true
+		case white && p.turn == White:
+			return Outcome{Status: StatusVariantEnd, Winner: White}, true
+		case white:
+			// black gets one last move to draw by reaching the goal too
+			for _, m := range p.legalMoves() {
+				if p.board[m.From].Type == King && m.To.Rank() == 7 {
+					return Outcome{}, false
+				}
+			}
+			return Outcome{Status: StatusVariantEnd, Winner: White}, true
+		}
+	}
+	return Outcome{}, false
+}
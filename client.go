@@ -0,0 +1,102 @@
+package lichess
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+/*
+ * CLIENT OPTIONS
+ */
+
+type ClientOption func(*Lichess)
+
+// NewLichess returns a client configured by options. The zero Lichess is
+// ready to use too, with the defaults.
+//
+// Until it is authenticated, with AuthenticateClient or SetClient, the
+// client is anonymous: the public endpoints, such as TV, leaderboards, user
+// profiles, the opening explorer, the tablebase or game exports, work
+// without a token, while the calls needing one fail with an error matching
+// ErrNotAuthenticated.
+func NewLichess(options ...ClientOption) *Lichess {
+	l := &Lichess{limits: &rateLimitState{}}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// WithHTTPClient sends the requests through client, e.g. to use a proxy.
+// Once authenticated, the token is added on top of client's transport.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(l *Lichess) {
+		l.baseClient = client
+	}
+}
+
+// WithBaseURL points the API requests at another instance, such as
+// https://lichess.dev or a test server.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(l *Lichess) {
+		l.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent of the requests. Lichess asks bots to
+// send one describing the bot and how to contact its author.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(l *Lichess) {
+		l.userAgent = userAgent
+	}
+}
+
+// WithTimeout bounds every request that is not a stream, streams are only
+// ended by their context.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(l *Lichess) {
+		l.timeout = timeout
+	}
+}
+
+// Authenticated reports whether the client has a token, it is anonymous
+// otherwise.
+func (l *Lichess) Authenticated() bool {
+	return l.authorized() != nil
+}
+
+// endpoint resolves path against the API, absolute URLs such as the ones
+// of the engine or explorer hosts are kept as is.
+func (l *Lichess) endpoint(path string) string {
+	if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
+		return path
+	}
+	if l.baseURL != "" {
+		return l.baseURL + path
+	}
+	return lichessURL + path
+}
+
+// withTimeout applies the configured timeout to the context of a request.
+func (l *Lichess) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, l.timeout)
+}
+
+// authorizedHTTP returns the custom client with the token of the
+// authenticated client added to its requests.
+func (l *Lichess) authorizedHTTP(authorized *AuthorizedClient) *http.Client {
+	token, ok := authorized.Client.Transport.(*oauth2.Transport)
+	if !ok {
+		return l.baseClient
+	}
+	client := *l.baseClient
+	client.Transport = &oauth2.Transport{Source: token.Source, Base: l.baseClient.Transport}
+	return &client
+}
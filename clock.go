@@ -0,0 +1,158 @@
+package lichess
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+/*
+ * CLOCK
+ */
+
+// Speed is a speed category, as used by the speed fields of games and
+// challenges.
+type Speed string
+
+const (
+	SpeedUltraBullet Speed = "ultraBullet"
+	SpeedBullet Speed = "bullet"
+	SpeedBlitz Speed = "blitz"
+	SpeedRapid Speed = "rapid"
+	SpeedClassical Speed = "classical"
+	SpeedCorrespondence Speed = "correspondence"
+)
+
+func (s Speed) String() string {
+	return string(s)
+}
+
+func (s Speed) Valid() bool {
+	switch s {
+	case SpeedUltraBullet, SpeedBullet, SpeedBlitz, SpeedRapid, SpeedClassical, SpeedCorrespondence:
+		return true
+	}
+	return false
+}
+
+// Millis converts a clock field in milliseconds, such as wtime, to a duration.
+func Millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Centis converts a clock field in centiseconds, as sent by the game export
+// and TV endpoints, to a duration.
+func Centis(cs int64) time.Duration {
+	return time.Duration(cs) * 10 * time.Millisecond
+}
+
+// millisTime converts a date in milliseconds since the epoch, zero being
+// the zero time.
+func millisTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func timeMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// InitialTime returns c.Initial, it predates the clock fields being
+// durations.
+func (c Clock) InitialTime() time.Duration {
+	return c.Initial
+}
+
+func (c Clock) IncrementTime() time.Duration {
+	return c.Increment
+}
+
+// String formats the clock as Lichess does, e.g. "3+2" or "½+0".
+func (c Clock) String() string {
+	return FormatTimeControl(c.InitialTime(), c.IncrementTime())
+}
+
+// Speed returns the speed category of the clock.
+func (c Clock) Speed() Speed {
+	return SpeedOf(c.InitialTime(), c.IncrementTime())
+}
+
+// TimeLeft returns the time left on the user's clock, or to move in a
+// correspondence game, as of the event.
+func (g Game) TimeLeft() time.Duration {
+	return time.Duration(g.SecondsLeft) * time.Second
+}
+
+// Clocks returns the remaining time of both players.
+func (s GameState) Clocks() (white time.Duration, black time.Duration) {
+	return s.WhiteTime, s.BlackTime
+}
+
+// Clocks returns the remaining time of both players sent with a message of
+// the move stream of a game.
+func (e GameMoveEvent) Clocks() (white time.Duration, black time.Duration) {
+	return time.Duration(e.WhiteClock) * time.Second, time.Duration(e.BlackClock) * time.Second
+}
+
+// SpeedOf classifies a time control the way Lichess does, from the estimated
+// game duration of initial + 40 * increment. A zero clock is correspondence.
+func SpeedOf(initial time.Duration, increment time.Duration) Speed {
+	if initial <= 0 && increment <= 0 {
+		return SpeedCorrespondence
+	}
+	estimate := initial + 40*increment
+	switch {
+	case estimate < 30*time.Second:
+		return SpeedUltraBullet
+	case estimate < 3*time.Minute:
+		return SpeedBullet
+	case estimate < 8*time.Minute:
+		return SpeedBlitz
+	case estimate < 25*time.Minute:
+		return SpeedRapid
+	}
+	return SpeedClassical
+}
+
+// FormatTimeControl formats the initial time in minutes and the increment in
+// seconds, e.g. "3+2". Quarter minutes are written with fraction signs.
+func FormatTimeControl(initial time.Duration, increment time.Duration) string {
+	var minutes string
+	switch initial {
+	case 15 * time.Second:
+		minutes = "¼"
+	case 30 * time.Second:
+		minutes = "½"
+	case 45 * time.Second:
+		minutes = "¾"
+	default:
+		minutes = strconv.FormatFloat(initial.Minutes(), 'f', -1, 64)
+	}
+	return fmt.Sprintf("%s+%d", minutes, int64(increment/time.Second))
+}
+
+// FormatClock formats a remaining time as "1:23:45", or "3:05" under an hour.
+// Tenths are shown under ten seconds, as on the Lichess clock.
+func FormatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d < 10*time.Second {
+		return fmt.Sprintf("0:%02d.%d", int64(d/time.Second), int64(d%time.Second/(100*time.Millisecond)))
+	}
+	s := int64(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// NewClock returns a real-time clock, as used by challenges and the board API.
+func NewClock(initial time.Duration, increment time.Duration) Clock {
+	return Clock{Initial: initial, Increment: increment}
+}
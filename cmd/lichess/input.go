@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// input reads the lines typed by the user in the background, so prompts can
+// be abandoned when a game ends or ctx is done.
+type input struct {
+	lines chan string
+}
+
+func newInput(r io.Reader) *input {
+	in := &input{lines: make(chan string)}
+	go func() {
+		defer close(in.lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			in.lines <- strings.TrimSpace(scanner.Text())
+		}
+	}()
+	return in
+}
+
+// line waits for the next line, ok is false once the input is closed or ctx
+// is done.
+func (in *input) line(ctx context.Context) (line string, ok bool) {
+	select {
+	case line, ok = <-in.lines:
+		return line, ok
+	case <-ctx.Done():
+		return "", false
+	}
+}
@@ -0,0 +1,243 @@
+// Command lichess plays on Lichess from a terminal through the board API,
+// e.g. over SSH.
+//
+// Usage:
+//
+//	lichess [flags] login | logout
+//	lichess [flags] seek [-rated] [-time minutes] [-increment seconds]
+//	lichess [flags] challenge [-rated] [-time minutes] [-increment seconds] username
+//	lichess [flags] wait
+//
+// login authenticates in the browser, printing the URL to open for machines
+// without one, and keeps the token for the other commands. A personal API
+// token with the board:play, challenge:read and challenge:write scopes can
+// be given instead with -token or LICHESS_TOKEN.
+//
+// seek looks for an opponent in the lobby, challenge invites a user, and
+// wait plays the games started elsewhere, asking whether to accept each
+// challenge received. Moves are typed in UCI (e2e4) or SAN (Nf3), along
+// with resign, abort, draw and quit.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/hmccarty/lichess"
+)
+
+// clientID names the app on the Lichess authorization page
+const clientID = "lichess-cli"
+
+var scopes = []string{
+	string(lichess.ScopeBoardPlay),
+	string(lichess.ScopeChallengeRead),
+	string(lichess.ScopeChallengeWrite),
+}
+
+func main() {
+	flag.Usage = usage
+	token := flag.String("token", os.Getenv("LICHESS_TOKEN"), "API token, instead of logging in")
+	baseURL := flag.String("url", "", "API server, e.g. https://lichess.dev with a token of it")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, *baseURL, *token, flag.Arg(0), flag.Args()[1:]); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "lichess:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: lichess [flags] login | logout | seek | challenge username | wait\n")
+	flag.PrintDefaults()
+}
+
+func run(ctx context.Context, baseURL string, token string, command string, args []string) error {
+	if command == "logout" {
+		store, err := tokenStore()
+		if err != nil {
+			return err
+		}
+		return store.Delete()
+	}
+
+	l, err := newClient(ctx, baseURL, token)
+	if err != nil {
+		return err
+	}
+	in := newInput(os.Stdin)
+	switch command {
+	case "login":
+		account, err := l.GetAccount(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("logged in as", account.Username)
+		return nil
+	case "seek":
+		return seek(ctx, l, in, args)
+	case "challenge":
+		return challenge(ctx, l, in, args)
+	case "wait":
+		return wait(ctx, l, in)
+	}
+	usage()
+	os.Exit(2)
+	return nil
+}
+
+// newClient authenticates with token, or with the stored token of an earlier
+// login, logging in through the browser if there is none.
+func newClient(ctx context.Context, baseURL string, token string) (*lichess.Lichess, error) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	options := []lichess.ClientOption{lichess.WithLogger(logger)}
+	if baseURL != "" {
+		options = append(options, lichess.WithBaseURL(baseURL))
+	}
+	l := lichess.NewLichess(options...)
+	if token != "" {
+		l.SetClient(lichess.NewClientWithToken(token))
+		return l, nil
+	}
+	store, err := tokenStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := l.AuthenticateClientPKCE(ctx, clientID, scopes, lichess.WithTokenStore(store)); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// tokenStore keeps the token in a file, the OS keyring being often missing
+// on the machines reached over SSH.
+func tokenStore() (lichess.TokenStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return lichess.NewFileTokenStore(filepath.Join(dir, "lichess", "token.json")), nil
+}
+
+// gameFlags are the flags setting up the game of seek and challenge.
+type gameFlags struct {
+	rated *bool
+	time *int
+	increment *int
+}
+
+func newGameFlags(fs *flag.FlagSet) gameFlags {
+	return gameFlags{
+		rated: fs.Bool("rated", false, "play a rated game"),
+		time: fs.Int("time", 10, "clock time in minutes"),
+		increment: fs.Int("increment", 0, "clock increment in seconds"),
+	}
+}
+
+func seek(ctx context.Context, l *lichess.Lichess, in *input, args []string) error {
+	fs := flag.NewFlagSet("seek", flag.ExitOnError)
+	game := newGameFlags(fs)
+	fs.Parse(args)
+
+	fmt.Printf("seeking a %d+%d game...\n", *game.time, *game.increment)
+	session, err := l.FindAndStartGame(ctx, lichess.SeekOptions{
+		Rated: *game.rated,
+		Time: *game.time,
+		Increment: *game.increment,
+	})
+	if err != nil {
+		return err
+	}
+	return play(ctx, session, in)
+}
+
+func challenge(ctx context.Context, l *lichess.Lichess, in *input, args []string) error {
+	fs := flag.NewFlagSet("challenge", flag.ExitOnError)
+	game := newGameFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("challenge takes the username of the opponent")
+	}
+
+	// the event stream is opened first so the gameStart is not missed
+	events := l.EventStream(ctx)
+	defer events.Close()
+	sent, err := l.ChallengeUser(ctx, fs.Arg(0), lichess.ChallengeOptions{
+		Rated: *game.rated,
+		Clock: &lichess.Clock{
+			Initial: time.Duration(*game.time) * time.Minute,
+			Increment: time.Duration(*game.increment) * time.Second,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("challenged %s, waiting for an answer...\n", fs.Arg(0))
+	for event := range events.Events() {
+		switch {
+		case event.Type == "challengeDeclined" && event.Challenge.ID == sent.ID:
+			return fmt.Errorf("%s declined the challenge", fs.Arg(0))
+		case event.Type == "gameStart" && event.Game.ID == sent.ID:
+			return play(ctx, l.NewGameSession(ctx, event.Game), in)
+		}
+	}
+	l.CancelChallenge(context.Background(), sent.ID)
+	return <-events.Errors()
+}
+
+// wait plays the games started on the event stream, one at a time, and asks
+// whether to accept the challenges received meanwhile.
+func wait(ctx context.Context, l *lichess.Lichess, in *input) error {
+	events := l.EventStream(ctx)
+	defer events.Close()
+	fmt.Println("waiting for challenges and games, ^C to quit")
+	for event := range events.Events() {
+		switch event.Type {
+		case "challenge":
+			c := event.Challenge
+			if c.Direction == "out" {
+				continue
+			}
+			fmt.Printf("%s (%d) challenges you to a %s %s game, accept? [y/n] ", c.Challenger.Name, c.Challenger.Rating, describeRated(c.Rated), c.TimeControl.Show)
+			answer, ok := in.line(ctx)
+			if !ok {
+				return ctx.Err()
+			}
+			var err error
+			if answer == "y" || answer == "yes" {
+				err = l.AcceptChallenge(ctx, c.ID)
+			} else {
+				err = l.DeclineChallenge(ctx, c.ID, lichess.DeclineGeneric)
+			}
+			if err != nil {
+				fmt.Println("lichess:", err)
+			}
+		case "gameStart":
+			if err := play(ctx, l.NewGameSession(ctx, event.Game), in); err != nil {
+				return err
+			}
+			fmt.Println("waiting for challenges and games, ^C to quit")
+		}
+	}
+	return <-events.Errors()
+}
+
+func describeRated(rated bool) string {
+	if rated {
+		return "rated"
+	}
+	return "casual"
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmccarty/lichess"
+	"github.com/hmccarty/lichess/chess"
+)
+
+const prompt = "move> "
+
+// play follows a game on the terminal until it is over, sending the moves
+// and commands typed by the user.
+func play(ctx context.Context, s *lichess.GameSession, in *input) error {
+	defer s.Close()
+	opponent := s.Game.Opponent
+	fmt.Printf("\nplaying %s against %s (%d), type help for the commands\n", s.Game.Color, opponent.Username, opponent.Rating)
+
+	updates, chat := s.Updates(), s.Chat()
+	for {
+		select {
+		case event, ok := <-updates:
+			if !ok {
+				return finish(s)
+			}
+			switch e := event.(type) {
+			case lichess.GameFull:
+				render(s)
+				drawOffer(s, e.State)
+			case lichess.GameState:
+				render(s)
+				drawOffer(s, e)
+			case lichess.OpponentGone:
+				if e.Gone {
+					fmt.Printf("\nyour opponent left, type claim to win in %ds\n%s", e.ClaimWinInSeconds, prompt)
+				}
+			case lichess.TakebackOffer:
+				if e.OfferedBy != string(s.Game.Color) {
+					fmt.Printf("\nyour opponent proposes a takeback, which this client does not support\n%s", prompt)
+				}
+			}
+		case line, ok := <-chat:
+			if !ok {
+				chat = nil
+				continue
+			}
+			fmt.Printf("\n[%s] %s: %s\n%s", line.Room, line.User, line.Text, prompt)
+		case line, ok := <-in.lines:
+			if !ok {
+				return nil
+			}
+			if quit := command(ctx, s, line); quit {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// command runs a line typed during the game, reporting whether the user
+// quit.
+func command(ctx context.Context, s *lichess.GameSession, line string) bool {
+	var err error
+	switch line {
+	case "":
+		fmt.Print(prompt)
+		return false
+	case "help":
+		fmt.Print("moves are typed in UCI (e2e4) or SAN (Nf3), along with\n" +
+			"  resign, abort, draw (offer or accept), claim (when the opponent left),\n" +
+			"  board and quit (leaving the game running)\n" + prompt)
+		return false
+	case "quit":
+		return true
+	case "board":
+		render(s)
+		return false
+	case "resign":
+		err = s.Game.Resign(ctx)
+	case "abort":
+		err = s.Game.Abort(ctx)
+	case "draw":
+		err = s.Game.OfferDraw(ctx)
+	case "claim":
+		err = s.Game.ClaimVictory(ctx)
+	default:
+		if !s.IsMyTurn() {
+			err = errors.New("not your turn")
+			break
+		}
+		var uci string
+		if uci, err = parseMove(s.Position(), line); err == nil {
+			err = s.Move(ctx, uci)
+		}
+	}
+	if err != nil {
+		fmt.Printf("%v\n%s", err, prompt)
+	}
+	return false
+}
+
+// parseMove reads a move in UCI or SAN, returning it in UCI.
+func parseMove(pos *chess.Position, s string) (string, error) {
+	if m, err := pos.ParseMove(s); err == nil {
+		return m.String(), nil
+	}
+	m, err := pos.ParseSAN(s)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a legal move", s)
+	}
+	return m.String(), nil
+}
+
+func drawOffer(s *lichess.GameSession, state lichess.GameState) {
+	offered := state.WhiteDraw
+	if s.Game.Color == lichess.ColorWhite {
+		offered = state.BlackDraw
+	}
+	if offered && state.Status.IsOngoing() {
+		fmt.Printf("your opponent offers a draw, type draw to accept\n%s", prompt)
+	}
+}
+
+// finish prints the result of the game once the session has ended.
+func finish(s *lichess.GameSession) error {
+	result, ok := s.Result()
+	if !ok {
+		return s.Err()
+	}
+	switch {
+	case result.Won():
+		fmt.Printf("\nyou won (%s)\n", result.Status)
+	case result.Lost():
+		fmt.Printf("\nyou lost (%s)\n", result.Status)
+	default:
+		fmt.Printf("\ndraw (%s)\n", result.Status)
+	}
+	return nil
+}
+
+// render prints the board from the side of the user, with the clocks and
+// the last move.
+func render(s *lichess.GameSession) {
+	pos := s.Position()
+	white := s.Game.Color != lichess.ColorBlack
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for i := 0; i < 8; i++ {
+		rank := 7 - i
+		if !white {
+			rank = i
+		}
+		fmt.Fprintf(&b, " %d ", rank+1)
+		for j := 0; j < 8; j++ {
+			file := j
+			if !white {
+				file = 7 - j
+			}
+			piece := pos.PieceAt(chess.NewSquare(file, rank))
+			if piece == chess.NoPiece {
+				b.WriteString(" .")
+			} else {
+				b.WriteString(" " + piece.Letter())
+			}
+		}
+		b.WriteString("\n")
+	}
+	if white {
+		b.WriteString("    a b c d e f g h\n")
+	} else {
+		b.WriteString("    h g f e d c b a\n")
+	}
+
+	fmt.Fprintf(&b, "white %s  black %s", clock(s.Clock("white")), clock(s.Clock("black")))
+	if last := s.LastMove(); last != "" {
+		fmt.Fprintf(&b, "  last move %s", last)
+	}
+	b.WriteString("\n")
+	switch {
+	case s.IsOver():
+	case s.IsMyTurn():
+		b.WriteString("your turn\n" + prompt)
+	default:
+		b.WriteString("waiting for your opponent\n" + prompt)
+	}
+	fmt.Print(b.String())
+}
+
+func clock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,202 @@
+package lichess
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+ * DECODING
+ */
+
+// SetStrictDecoding makes the client report the fields of the responses it
+// does not know, to notice when Lichess adds or renames one. Responses are
+// still decoded in full, then requests fail with an *UnknownFieldError and
+// streams log a warning and go on.
+func (l *Lichess) SetStrictDecoding(strict bool) {
+	l.strict = strict
+}
+
+func WithStrictDecoding() ClientOption {
+	return func(l *Lichess) {
+		l.strict = true
+	}
+}
+
+// unmarshal decodes a response into v, reporting its unknown fields in
+// strict mode.
+func (l *Lichess) unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil || !l.strict {
+		return err
+	}
+	return unknownField(data, v)
+}
+
+// unmarshalLine decodes a line of a stream into v, where unknown fields are
+// only logged.
+func (l *Lichess) unmarshalLine(line []byte, v interface{}) error {
+	if err := json.Unmarshal(line, v); err != nil {
+		return err
+	}
+	l.checkLine(line, v)
+	return nil
+}
+
+// checkLine logs the unknown fields of a line decoded into v, in strict
+// mode. The known top-level keys, such as the type of board events, are not
+// reported.
+func (l *Lichess) checkLine(line []byte, v interface{}, known ...string) {
+	if !l.strict {
+		return
+	}
+	if unknown, ok := unknownField(line, v, known...).(*UnknownFieldError); ok {
+		l.log().Warn("lichess: unknown field in stream", "type", unknown.Type, "field", unknown.Field)
+	}
+}
+
+// jsonAliaser is implemented by the types with their own UnmarshalJSON. It
+// returns the struct they decode their JSON objects into, which the strict
+// check looks at in their place.
+type jsonAliaser interface {
+	jsonAlias() interface{}
+}
+
+var (
+	jsonAliaserType = reflect.TypeOf((*jsonAliaser)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// unknownField returns an *UnknownFieldError for the first key of data that
+// the type of v, or the types it holds, has no field for. Values of types
+// decoding themselves are checked through their jsonAlias, or skipped.
+func unknownField(data []byte, v interface{}, known ...string) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	var err error
+	checkFields(data, t, known, &err)
+	return err
+}
+
+func checkFields(data []byte, t reflect.Type, known []string, err *error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := t.String()
+	if reflect.PointerTo(t).Implements(jsonAliaserType) {
+		t = reflect.TypeOf(reflect.New(t).Interface().(jsonAliaser).jsonAlias()).Elem()
+	} else if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(object) {
+			field, ok := lookupField(fields, key)
+			switch {
+			case ok:
+				checkFields(object[key], field, nil, err)
+			case !containsString(known, key):
+				*err = &UnknownFieldError{Type: name, Field: key}
+			}
+			if *err != nil {
+				return
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return
+		}
+		for _, item := range items {
+			if checkFields(item, t.Elem(), nil, err); *err != nil {
+				return
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if json.Unmarshal(data, &values) != nil {
+			return
+		}
+		for _, key := range sortedKeys(values) {
+			if checkFields(values[key], t.Elem(), nil, err); *err != nil {
+				return
+			}
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of struct t by JSON key, those
+// of embedded structs included.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if reflect.PointerTo(ft).Implements(jsonAliaserType) {
+			ft = reflect.TypeOf(reflect.New(ft).Interface().(jsonAliaser).jsonAlias()).Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			for key, embedded := range jsonFields(ft) {
+				if _, ok := fields[key]; !ok {
+					fields[key] = embedded
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		fields[tag] = f.Type
+	}
+	return fields
+}
+
+// lookupField matches key like encoding/json, case-insensitively.
+func lookupField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if t, ok := fields[key]; ok {
+		return t, true
+	}
+	for name, t := range fields {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,330 @@
+package lichess
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * ENGINES
+ */
+
+// EnginePosition is the position an Engine is asked to move in.
+type EnginePosition struct {
+	Variant string
+	// FEN is the initial position of the game and Moves the moves played
+	// since, in UCI notation
+	FEN string
+	Moves []string
+	// the clocks are zero for games without a clock
+	WhiteTime time.Duration
+	BlackTime time.Duration
+	Increment time.Duration
+}
+
+// Engine chooses the moves of a bot.
+type Engine interface {
+	// BestMove returns the move to play in UCI notation. It should return
+	// soon once ctx is done.
+	BestMove(ctx context.Context, pos EnginePosition) (string, error)
+}
+
+// EngineFunc lets a plain function be used as an Engine.
+type EngineFunc func(ctx context.Context, pos EnginePosition) (string, error)
+
+func (f EngineFunc) BestMove(ctx context.Context, pos EnginePosition) (string, error) {
+	return f(ctx, pos)
+}
+
+// ErrEngineClosed is returned by the UCI engine once its process has exited.
+var ErrEngineClosed = errors.New("lichess: engine closed")
+
+// Untimed games are searched this long by default.
+const defaultMoveTime = 5 * time.Second
+
+// UCIEngine runs an engine speaking the UCI protocol, such as Stockfish, as a
+// child process. It is safe for concurrent use, searches are run one at a
+// time.
+type UCIEngine struct {
+	// MoveTime is the time spent on a move in games without a clock
+	MoveTime time.Duration
+
+	mu sync.Mutex
+	cmd *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+	chess960 bool
+}
+
+// NewUCIEngine starts the engine at path and sets options, e.g. "Threads" or
+// "Hash", once it is ready.
+func NewUCIEngine(ctx context.Context, path string, options map[string]string, args ...string) (*UCIEngine, error) {
+	cmd := exec.Command(path, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	e := &UCIEngine{
+		MoveTime: defaultMoveTime,
+		cmd: cmd,
+		stdin: stdin,
+		lines: make(chan string),
+	}
+	go func() {
+		defer close(e.lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			e.lines <- scanner.Text()
+		}
+	}()
+
+	if err := e.handshake(ctx, options); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("lichess: starting engine %s: %w", path, err)
+	}
+	return e, nil
+}
+
+func (e *UCIEngine) handshake(ctx context.Context, options map[string]string) error {
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+	if _, err := e.await(ctx, "uciok"); err != nil {
+		return err
+	}
+	for name, value := range options {
+		if err := e.send("setoption name " + name + " value " + value); err != nil {
+			return err
+		}
+	}
+	return e.ready(ctx)
+}
+
+func (e *UCIEngine) ready(ctx context.Context) error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	_, err := e.await(ctx, "readyok")
+	return err
+}
+
+func (e *UCIEngine) send(command string) error {
+	_, err := io.WriteString(e.stdin, command+"\n")
+	return err
+}
+
+// await reads the engine output up to the line starting with prefix.
+func (e *UCIEngine) await(ctx context.Context, prefix string) (string, error) {
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return "", ErrEngineClosed
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line, nil
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// BestMove searches pos within the time left on the clock. When ctx is done
+// the search is stopped and the best move found so far is returned.
+func (e *UCIEngine) BestMove(ctx context.Context, pos EnginePosition) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if chess960 := pos.Variant == "chess960"; chess960 != e.chess960 {
+		if err := e.send("setoption name UCI_Chess960 value " + strconv.FormatBool(chess960)); err != nil {
+			return "", err
+		}
+		e.chess960 = chess960
+	}
+	position := "position startpos"
+	if pos.FEN != "" && pos.FEN != "startpos" {
+		position = "position fen " + pos.FEN
+	}
+	if len(pos.Moves) > 0 {
+		position += " moves " + strings.Join(pos.Moves, " ")
+	}
+	if err := e.send(position); err != nil {
+		return "", err
+	}
+	if err := e.send(e.goCommand(pos)); err != nil {
+		return "", err
+	}
+
+	line, err := e.await(ctx, "bestmove")
+	if err == ErrEngineClosed {
+		return "", err
+	}
+	if err != nil {
+		// the engine still answers the search, with its best move so far
+		if err := e.send("stop"); err != nil {
+			return "", err
+		}
+		if line, err = e.await(context.Background(), "bestmove"); err != nil {
+			return "", err
+		}
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[1] == "(none)" {
+		return "", fmt.Errorf("lichess: engine found no move: %q", line)
+	}
+	return fields[1], nil
+}
+
+func (e *UCIEngine) goCommand(pos EnginePosition) string {
+	if pos.WhiteTime <= 0 && pos.BlackTime <= 0 {
+		return fmt.Sprintf("go movetime %d", e.MoveTime.Milliseconds())
+	}
+	return fmt.Sprintf("go wtime %d btime %d winc %d binc %d",
+		pos.WhiteTime.Milliseconds(), pos.BlackTime.Milliseconds(),
+		pos.Increment.Milliseconds(), pos.Increment.Milliseconds())
+}
+
+// Close asks the engine to quit and waits for its process to exit.
+func (e *UCIEngine) Close() error {
+	e.send("quit")
+	e.stdin.Close()
+	for range e.lines {
+	}
+	return e.cmd.Wait()
+}
+
+/*
+ * BOT RUNNER
+ */
+
+// Bot plays the games of the authenticated user with Engine.
+type Bot struct {
+	Engine Engine
+	// OnChallenge answers the incoming challenges, they are left pending if
+	// nil
+	OnChallenge func(Challenge) Decision
+	// OnError is told about the moves and challenges that failed
+	OnError func(error)
+}
+
+// RunBot plays every game of the authenticated user, those already ongoing
+// included, until ctx is done or the event stream fails. It returns once
+// the games being played have stopped.
+func (l *Lichess) RunBot(ctx context.Context, bot Bot) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sessions := map[string]*GameSession{}
+	errs := l.RegisterHandler(ctx, EventHandler{
+		OnChallenge: bot.OnChallenge,
+		OnGameStart: func(game Game) {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := sessions[game.ID]; ok {
+				return
+			}
+			session := l.NewGameSession(ctx, game)
+			sessions[game.ID] = session
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bot.play(ctx, session)
+				mu.Lock()
+				delete(sessions, game.ID)
+				mu.Unlock()
+			}()
+		},
+		OnGameFinish: func(game Game) {
+			mu.Lock()
+			defer mu.Unlock()
+			if session, ok := sessions[game.ID]; ok {
+				session.Finish(game)
+			}
+		},
+		OnError: bot.OnError,
+	})
+
+	err := <-errs
+	cancel()
+	wg.Wait()
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// play moves in session whenever it is the user's turn, until the game is
+// over.
+func (b Bot) play(ctx context.Context, s *GameSession) {
+	defer s.Close()
+	turn := make(chan struct{}, 1)
+	go func() {
+		defer close(turn)
+		for range s.Updates() {
+			select {
+			case turn <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	// moved is the ply of the last move sent, until the stream reports it
+	moved := -1
+	for range turn {
+		pos, ply := s.enginePosition()
+		if s.IsOver() || !s.IsMyTurn() || ply == moved {
+			continue
+		}
+		move, err := b.Engine.BestMove(ctx, pos)
+		if err == nil {
+			err = s.Move(ctx, move)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.report(fmt.Errorf("game %s: %w", s.Game.ID, err))
+			continue
+		}
+		moved = ply
+	}
+}
+
+func (b Bot) report(err error) {
+	if b.OnError != nil {
+		b.OnError(err)
+	}
+}
+
+// enginePosition returns the current position as given to engines, along
+// with the number of moves played.
+func (s *GameSession) enginePosition() (EnginePosition, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return EnginePosition{
+		Variant: s.start.Variant().String(),
+		FEN: s.start.FEN(),
+		Moves: append([]string(nil), s.moves...),
+		WhiteTime: s.whiteClock,
+		BlackTime: s.blackClock,
+		Increment: s.clock.IncrementTime(),
+	}, len(s.moves)
+}
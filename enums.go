@@ -0,0 +1,104 @@
+package lichess
+
+/*
+ * ENUMS
+ */
+
+// VariantKey is the key of a variant, as sent in the key of Variant and
+// accepted by the variant parameters.
+type VariantKey string
+
+const (
+	VariantStandard VariantKey = "standard"
+	VariantChess960 VariantKey = "chess960"
+	VariantCrazyhouse VariantKey = "crazyhouse"
+	VariantAntichess VariantKey = "antichess"
+	VariantAtomic VariantKey = "atomic"
+	VariantHorde VariantKey = "horde"
+	VariantKingOfTheHill VariantKey = "kingOfTheHill"
+	VariantRacingKings VariantKey = "racingKings"
+	VariantThreeCheck VariantKey = "threeCheck"
+	VariantFromPosition VariantKey = "fromPosition"
+)
+
+func (v VariantKey) String() string {
+	return string(v)
+}
+
+// Valid reports whether v is a variant known to Lichess.
+func (v VariantKey) Valid() bool {
+	switch v {
+	case VariantStandard, VariantChess960, VariantCrazyhouse, VariantAntichess,
+		VariantAtomic, VariantHorde, VariantKingOfTheHill, VariantRacingKings,
+		VariantThreeCheck, VariantFromPosition:
+		return true
+	}
+	return false
+}
+
+// Color is a side of a game. ColorRandom is only accepted when creating
+// seeks and challenges.
+type Color string
+
+const (
+	ColorWhite Color = "white"
+	ColorBlack Color = "black"
+	ColorRandom Color = "random"
+)
+
+func (c Color) String() string {
+	return string(c)
+}
+
+func (c Color) Valid() bool {
+	return c == ColorWhite || c == ColorBlack || c == ColorRandom
+}
+
+// Opposite returns the other side, ColorRandom is its own opposite.
+func (c Color) Opposite() Color {
+	switch c {
+	case ColorWhite:
+		return ColorBlack
+	case ColorBlack:
+		return ColorWhite
+	}
+	return c
+}
+
+// GameStatus is the name of the status of a game, e.g. "started" or "mate".
+type GameStatus string
+
+const (
+	StatusCreated GameStatus = "created"
+	StatusStarted GameStatus = "started"
+	StatusAborted GameStatus = "aborted"
+	StatusMate GameStatus = "mate"
+	StatusResign GameStatus = "resign"
+	StatusStalemate GameStatus = "stalemate"
+	StatusTimeout GameStatus = "timeout"
+	StatusDraw GameStatus = "draw"
+	StatusOutOfTime GameStatus = "outoftime"
+	StatusCheat GameStatus = "cheat"
+	StatusNoStart GameStatus = "noStart"
+	StatusUnknownFinish GameStatus = "unknownFinish"
+	StatusVariantEnd GameStatus = "variantEnd"
+)
+
+func (s GameStatus) String() string {
+	return string(s)
+}
+
+func (s GameStatus) Valid() bool {
+	switch s {
+	case StatusCreated, StatusStarted, StatusAborted, StatusMate, StatusResign,
+		StatusStalemate, StatusTimeout, StatusDraw, StatusOutOfTime, StatusCheat,
+		StatusNoStart, StatusUnknownFinish, StatusVariantEnd:
+		return true
+	}
+	return false
+}
+
+// IsOngoing reports whether a game with this status can still receive moves.
+func (s GameStatus) IsOngoing() bool {
+	return isOngoing(string(s))
+}
@@ -0,0 +1,179 @@
+package lichess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*
+ * ERRORS
+ */
+
+// ErrServiceUnavailable is matched (with errors.Is) by every error caused by
+// Lichess being down or in a maintenance window.
+var ErrServiceUnavailable = errors.New("lichess: service unavailable")
+
+type ServiceUnavailableError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the server, 0 if none was given
+	RetryAfter time.Duration
+}
+
+func (e *ServiceUnavailableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v (status %d, retry after %s)", ErrServiceUnavailable, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("%v (status %d)", ErrServiceUnavailable, e.StatusCode)
+}
+
+func (e *ServiceUnavailableError) Is(target error) bool {
+	return target == ErrServiceUnavailable
+}
+
+// ErrStream is matched by the errors Lichess reports inside a stream, after
+// the response itself succeeded.
+var ErrStream = errors.New("lichess: stream error")
+
+// StreamError is an {"error": "..."} line received in an NDJSON stream, for
+// instance when a followed game becomes private.
+type StreamError struct {
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrStream, e.Message)
+}
+
+func (e *StreamError) Is(target error) bool {
+	return target == ErrStream
+}
+
+// ErrStreamStalled ends a stream, or makes it reconnect, once nothing was
+// received in the idle timeout, not even a keep-alive line.
+var ErrStreamStalled = errors.New("lichess: stream stalled")
+
+// ErrUnknownField is matched by the errors of strict clients decoding a
+// field they do not know.
+var ErrUnknownField = errors.New("lichess: unknown field")
+
+type UnknownFieldError struct {
+	// Type is the Go type being decoded, Field the JSON key it lacks
+	Type string
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("%v %q decoding %s", ErrUnknownField, e.Field, e.Type)
+}
+
+func (e *UnknownFieldError) Is(target error) bool {
+	return target == ErrUnknownField
+}
+
+// ErrRateLimited is matched by the errors of requests rejected with 429 Too
+// Many Requests.
+var ErrRateLimited = errors.New("lichess: rate limited")
+
+// Lichess asks clients to wait a full minute after a 429.
+const rateLimitCooldown = time.Minute
+
+type RateLimitError struct {
+	// RetryAfter is the delay to wait before the next request
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", ErrRateLimited, e.RetryAfter)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// APIError is an unsuccessful response of the Lichess API.
+type APIError struct {
+	StatusCode int
+	Method string
+	Path string
+	// Message is the error Lichess gave in the body, if any
+	Message string
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("lichess: %s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is matches ErrNotAuthenticated for 401 responses, sent for a missing,
+// expired or revoked token.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotAuthenticated && e.StatusCode == http.StatusUnauthorized
+}
+
+// Only the start of error bodies is kept in APIError.
+const maxErrorBody = 4 << 10
+
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Method: resp.Request.Method,
+		Path: resp.Request.URL.Path,
+	}
+	e.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	// {"error": "message"}, or a map of fields to messages for invalid forms
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(e.Body, &body) != nil || body.Error == nil {
+		return e
+	}
+	if json.Unmarshal(body.Error, &e.Message) != nil {
+		e.Message = string(body.Error)
+	}
+	return e
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	s := resp.Header.Get("Retry-After")
+	if s == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(s); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// checkResponse converts unsuccessful responses into errors. The body is
+// partially read for APIError, and left for the caller to close.
+func checkResponse(resp *http.Response) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return nil
+	// maintenance pages are served as 503, the proxy answers 502/504 while
+	// the servers restart
+	case resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return &ServiceUnavailableError{resp.StatusCode, retryAfter(resp)}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		delay := retryAfter(resp)
+		if delay < rateLimitCooldown {
+			delay = rateLimitCooldown
+		}
+		return &RateLimitError{delay}
+	}
+	return newAPIError(resp)
+}
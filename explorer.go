@@ -0,0 +1,172 @@
+package lichess
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * OPENING EXPLORER
+ */
+
+// The opening explorer is served by its own host.
+const explorerURL = "https://explorer.lichess.ovh"
+
+// GET
+const mastersExplorerPath = explorerURL + "/masters"
+const lichessExplorerPath = explorerURL + "/lichess"
+const playerExplorerPath = explorerURL + "/player"
+
+// Explorer is the statistics of the games that reached a position.
+type Explorer struct {
+	// Opening is nil for positions outside of the opening book
+	Opening *ExplorerOpening `json:"opening"`
+	White int `json:"white"`
+	Draws int `json:"draws"`
+	Black int `json:"black"`
+	Moves []ExplorerMove `json:"moves"`
+	TopGames []ExplorerGame `json:"topGames,omitempty"`
+	RecentGames []ExplorerGame `json:"recentGames,omitempty"`
+	// QueuePosition is set by the player database while it is still
+	// indexing the games of the player
+	QueuePosition int `json:"queuePosition,omitempty"`
+}
+
+type ExplorerOpening struct {
+	ECO string `json:"eco"`
+	Name string `json:"name"`
+}
+
+// ExplorerMove is a move played in the position, with the results of the
+// games that followed it.
+type ExplorerMove struct {
+	UCI string `json:"uci"`
+	SAN string `json:"san"`
+	AverageRating int `json:"averageRating"`
+	White int `json:"white"`
+	Draws int `json:"draws"`
+	Black int `json:"black"`
+	// Game is set when the move was played in a single game
+	Game *ExplorerGame `json:"game,omitempty"`
+}
+
+type ExplorerGame struct {
+	ID string `json:"id"`
+	// UCI is the move played in the position, if known
+	UCI string `json:"uci,omitempty"`
+	// Winner is "white", "black" or empty for draws
+	Winner string `json:"winner,omitempty"`
+	Speed string `json:"speed,omitempty"`
+	Mode string `json:"mode,omitempty"`
+	White ExplorerPlayer `json:"white"`
+	Black ExplorerPlayer `json:"black"`
+	Year int `json:"year"`
+	Month string `json:"month,omitempty"`
+}
+
+type ExplorerPlayer struct {
+	Name string `json:"name"`
+	Rating int `json:"rating"`
+}
+
+// ExplorerOptions selects the position and the games to explore. Not every
+// database supports every filter: Variant, Speeds and Ratings are ignored by
+// the masters database, which also only keeps the year of Since and Until.
+type ExplorerOptions struct {
+	Variant string
+	// FEN is the initial position, Play the moves played since in UCI
+	// notation
+	FEN string
+	Play []string
+	// Speeds are e.g. "blitz" or "rapid"
+	Speeds []string
+	// Ratings are the lower bounds of the rating groups to include, e.g.
+	// 1600 or 2500
+	Ratings []int
+	// Modes are "rated" and "casual", for the player database
+	Modes []string
+	Since time.Time
+	Until time.Time
+	// Moves, TopGames and RecentGames bound the number of entries returned,
+	// Lichess picks them if zero
+	Moves int
+	TopGames int
+	RecentGames int
+}
+
+func (o ExplorerOptions) values(masters bool) url.Values {
+	v := url.Values{}
+	if o.Variant != "" && !masters {
+		v.Set("variant", o.Variant)
+	}
+	if o.FEN != "" {
+		v.Set("fen", o.FEN)
+	}
+	if len(o.Play) > 0 {
+		v.Set("play", strings.Join(o.Play, ","))
+	}
+	if !masters {
+		if len(o.Speeds) > 0 {
+			v.Set("speeds", strings.Join(o.Speeds, ","))
+		}
+		if len(o.Ratings) > 0 {
+			ratings := make([]string, len(o.Ratings))
+			for i, r := range o.Ratings {
+				ratings[i] = strconv.Itoa(r)
+			}
+			v.Set("ratings", strings.Join(ratings, ","))
+		}
+		if len(o.Modes) > 0 {
+			v.Set("modes", strings.Join(o.Modes, ","))
+		}
+	}
+	// the masters database is split by year, the others by month
+	layout := "2006-01"
+	if masters {
+		layout = "2006"
+	}
+	if !o.Since.IsZero() {
+		v.Set("since", o.Since.Format(layout))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(layout))
+	}
+	if o.Moves > 0 {
+		v.Set("moves", strconv.Itoa(o.Moves))
+	}
+	if o.TopGames > 0 {
+		v.Set("topGames", strconv.Itoa(o.TopGames))
+	}
+	if o.RecentGames > 0 && !masters {
+		v.Set("recentGames", strconv.Itoa(o.RecentGames))
+	}
+	return v
+}
+
+// GetMastersExplorer explores the over the board games of masters.
+func (l *Lichess) GetMastersExplorer(ctx context.Context, opts ExplorerOptions) (Explorer, error) {
+	explorer := Explorer{}
+	err := l.getJSON(ctx, withQuery(mastersExplorerPath, opts.values(true)), &explorer)
+	return explorer, err
+}
+
+// GetLichessExplorer explores a sample of the rated games played on Lichess.
+func (l *Lichess) GetLichessExplorer(ctx context.Context, opts ExplorerOptions) (Explorer, error) {
+	explorer := Explorer{}
+	err := l.getJSON(ctx, withQuery(lichessExplorerPath, opts.values(false)), &explorer)
+	return explorer, err
+}
+
+// StreamPlayerExplorer explores the games of player with color, "white" or
+// "black". Lichess indexes the games of the player on demand and sends
+// updated statistics as it goes, each value replaces the previous one and
+// the last is complete.
+func (l *Lichess) StreamPlayerExplorer(ctx context.Context, player string, color string, opts ExplorerOptions) *Stream[Explorer] {
+	v := opts.values(false)
+	v.Set("player", player)
+	v.Set("color", color)
+	return newStream(ctx, l, streamSpec[Explorer]{path: withQuery(playerExplorerPath, v)})
+}
@@ -0,0 +1,262 @@
+package lichess
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+/*
+ * EXTERNAL ENGINES
+ */
+
+// The work of external engines is handed out by its own host.
+const engineURL = "https://engine.lichess.ovh"
+
+// GET
+const externalEnginesPath = "/api/external-engine"
+const externalEnginePath = "/api/external-engine/%s" // EngineID
+
+// POST
+const registerEnginePath = "/api/external-engine"
+const acquireEngineWorkPath = engineURL + "/api/external-engine/work"
+const answerEngineWorkPath = engineURL + "/api/external-engine/work/%s" // WorkID
+
+// PUT, DELETE
+const updateEnginePath = "/api/external-engine/%s" // EngineID
+
+// ExternalEngine is an engine running outside of Lichess, on hardware of a
+// provider, that a user can analyse with.
+type ExternalEngine struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	// ClientSecret lets the user request analysis from the engine
+	ClientSecret string `json:"clientSecret"`
+	UserID string `json:"userId"`
+	MaxThreads int `json:"maxThreads"`
+	// MaxHash is in MiB
+	MaxHash int `json:"maxHash"`
+	DefaultDepth int `json:"defaultDepth"`
+	Variants []string `json:"variants"`
+	ProviderData string `json:"providerData,omitempty"`
+}
+
+// ExternalEngineOptions registers or updates an external engine.
+// ProviderSecret is chosen by the provider, who later acquires work with it.
+type ExternalEngineOptions struct {
+	Name string `json:"name"`
+	MaxThreads int `json:"maxThreads"`
+	MaxHash int `json:"maxHash"`
+	DefaultDepth int `json:"defaultDepth"`
+	// Variants are UCI_Variant names, "chess" if empty
+	Variants []string `json:"variants,omitempty"`
+	ProviderSecret string `json:"providerSecret"`
+	ProviderData string `json:"providerData,omitempty"`
+}
+
+// RegisterExternalEngine adds an engine to the account of the authenticated
+// user.
+func (l *Lichess) RegisterExternalEngine(ctx context.Context, opts ExternalEngineOptions) (ExternalEngine, error) {
+	if err := l.authorized().requireScope(ScopeEngineWrite); err != nil {
+		return ExternalEngine{}, err
+	}
+	engine := ExternalEngine{}
+	err := l.sendJSON(ctx, http.MethodPost, registerEnginePath, opts, &engine)
+	return engine, err
+}
+
+// ListExternalEngines returns the engines registered by the authenticated
+// user.
+func (l *Lichess) ListExternalEngines(ctx context.Context) ([]ExternalEngine, error) {
+	if err := l.authorized().requireScope(ScopeEngineRead); err != nil {
+		return nil, err
+	}
+	engines := []ExternalEngine{}
+	err := l.getJSON(ctx, externalEnginesPath, &engines)
+	return engines, err
+}
+
+func (l *Lichess) GetExternalEngine(ctx context.Context, id string) (ExternalEngine, error) {
+	if err := l.authorized().requireScope(ScopeEngineRead); err != nil {
+		return ExternalEngine{}, err
+	}
+	engine := ExternalEngine{}
+	err := l.getJSON(ctx, fmt.Sprintf(externalEnginePath, url.PathEscape(id)), &engine)
+	return engine, err
+}
+
+// UpdateExternalEngine replaces all the properties of an engine with opts.
+func (l *Lichess) UpdateExternalEngine(ctx context.Context, id string, opts ExternalEngineOptions) (ExternalEngine, error) {
+	if err := l.authorized().requireScope(ScopeEngineWrite); err != nil {
+		return ExternalEngine{}, err
+	}
+	engine := ExternalEngine{}
+	err := l.sendJSON(ctx, http.MethodPut, fmt.Sprintf(updateEnginePath, url.PathEscape(id)), opts, &engine)
+	return engine, err
+}
+
+func (l *Lichess) DeleteExternalEngine(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeEngineWrite); err != nil {
+		return err
+	}
+	return l.sendJSON(ctx, http.MethodDelete, fmt.Sprintf(updateEnginePath, url.PathEscape(id)), nil, nil)
+}
+
+// EngineWork is an analysis requested from an external engine.
+type EngineWork struct {
+	ID string `json:"id"`
+	Work EngineWorkRequest `json:"work"`
+	Engine ExternalEngine `json:"engine"`
+}
+
+type EngineWorkRequest struct {
+	// SessionID is shared by the successive works of an analysis, a new
+	// work supersedes the previous one of its session
+	SessionID string `json:"sessionId"`
+	Threads int `json:"threads"`
+	Hash int `json:"hash"`
+	// Infinite works are analysed until the answer is closed by Lichess,
+	// others up to the default depth of the engine
+	Infinite bool `json:"infinite"`
+	MultiPV int `json:"multiPv"`
+	Variant string `json:"variant"`
+	InitialFEN string `json:"initialFen"`
+	// Moves are in UCI notation
+	Moves []string `json:"moves"`
+}
+
+// AcquireEngineWork waits for an analysis to run on one of the engines
+// registered with providerSecret. It returns nil if none was requested
+// before Lichess ended the wait, the caller should then try again.
+func (l *Lichess) AcquireEngineWork(ctx context.Context, providerSecret string) (*EngineWork, error) {
+	body, err := json.Marshal(struct {
+		ProviderSecret string `json:"providerSecret"`
+	}{providerSecret})
+	if err != nil {
+		return nil, err
+	}
+	req, err := l.newRequest(ctx, http.MethodPost, acquireEngineWorkPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	data, err := readBody(resp.Body, l.bodyLimit())
+	if err != nil {
+		return nil, err
+	}
+	work := &EngineWork{}
+	if err := l.unmarshal(data, work); err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+// AnswerEngineWork streams the UCI output of the engine, as read from r, to
+// Lichess until r ends or ctx is done.
+func (l *Lichess) AnswerEngineWork(ctx context.Context, workID string, r io.Reader) error {
+	req, err := l.newRequest(ctx, http.MethodPost, fmt.Sprintf(answerEngineWorkPath, url.PathEscape(workID)), r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// EngineWorkFunc runs the analysis of work, writing the UCI output of the
+// engine ("info" and "bestmove" lines) to w. It must return soon once ctx is
+// done.
+type EngineWorkFunc func(ctx context.Context, work EngineWork, w io.Writer) error
+
+// ServeExternalEngine acquires the works of the engines registered with
+// providerSecret and answers each with analyse, until ctx is done or
+// acquiring fails. A new work of a session stops the previous one.
+func (l *Lichess) ServeExternalEngine(ctx context.Context, providerSecret string, analyse EngineWorkFunc) error {
+	ctx, stop := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer stop()
+
+	var mu sync.Mutex
+	// the running work of each session
+	sessions := map[string]runningWork{}
+
+	for {
+		work, err := l.AcquireEngineWork(ctx, providerSecret)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if work == nil {
+			continue
+		}
+
+		workCtx, cancel := context.WithCancel(ctx)
+		session := work.Work.SessionID
+		mu.Lock()
+		if previous, ok := sessions[session]; ok {
+			previous.cancel()
+		}
+		sessions[session] = runningWork{work.ID, cancel}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(work EngineWork) {
+			defer wg.Done()
+			l.answer(workCtx, work, analyse)
+			cancel()
+			mu.Lock()
+			if sessions[session].id == work.ID {
+				delete(sessions, session)
+			}
+			mu.Unlock()
+		}(*work)
+	}
+}
+
+type runningWork struct {
+	id string
+	cancel context.CancelFunc
+}
+
+// answer pipes the output of analyse into the answer of work, and returns
+// once both are done.
+func (l *Lichess) answer(ctx context.Context, work EngineWork, analyse EngineWorkFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(analyse(ctx, work, pw))
+	}()
+	// the answer ending, e.g. once Lichess no longer needs it, stops the
+	// analysis
+	l.AnswerEngineWork(ctx, work.ID, pr)
+	cancel()
+	pr.Close()
+	<-done
+}
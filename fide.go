@@ -0,0 +1,44 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+/*
+ * FIDE
+ */
+
+// GET
+const fidePlayerPath = "/api/fide/player/%s" // FideID
+const searchFidePlayersPath = "/api/fide/player"
+
+// FidePlayer is a player of the FIDE rating list. The ratings are zero for
+// the time controls the player is not rated in.
+type FidePlayer struct {
+	ID int `json:"id"`
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Federation string `json:"federation"`
+	// Year is the year of birth
+	Year int `json:"year,omitempty"`
+	Inactive bool `json:"inactive,omitempty"`
+	Standard int `json:"standard,omitempty"`
+	Rapid int `json:"rapid,omitempty"`
+	Blitz int `json:"blitz,omitempty"`
+}
+
+func (l *Lichess) GetFidePlayer(ctx context.Context, fideID int) (FidePlayer, error) {
+	player := FidePlayer{}
+	err := l.getJSON(ctx, fmt.Sprintf(fidePlayerPath, strconv.Itoa(fideID)), &player)
+	return player, err
+}
+
+// SearchFidePlayers returns the FIDE players whose name matches query.
+func (l *Lichess) SearchFidePlayers(ctx context.Context, query string) ([]FidePlayer, error) {
+	players := []FidePlayer{}
+	err := l.getJSON(ctx, withQuery(searchFidePlayersPath, url.Values{"q": {query}}), &players)
+	return players, err
+}
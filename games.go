@@ -0,0 +1,366 @@
+package lichess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * GAMES
+ */
+
+// GET
+const exportGamePath = "/game/export/%s" // GameID
+const exportUserGamesPath = "/api/games/user/%s" // Username
+const streamGameMovesPath = "/api/stream/game/%s" // GameID
+const nowPlayingPath = "/api/account/playing"
+const currentGamePath = "/api/user/%s/current-game" // Username
+
+// POST
+const exportGamesByIDsPath = "/api/games/export/_ids"
+const streamGamesByIDsPath = "/api/stream/games/%s" // StreamID
+const addGamesToStreamPath = "/api/stream/games/%s/add" // StreamID
+const importGamePath = "/api/import"
+
+// Lichess accepts this many IDs per request for the games by ID endpoints.
+const MaxGameIDs = 300
+
+// GameMoveEvent is a message of the public move stream of a game. The first
+// message describes the game, the following ones carry one move each.
+type GameMoveEvent struct {
+	// Game description
+	ID string `json:"id,omitempty"`
+	Variant Variant `json:"variant,omitempty"`
+	Speed string `json:"speed,omitempty"`
+	Rated bool `json:"rated,omitempty"`
+	InitialFen string `json:"initialFen,omitempty"`
+	Turns int `json:"turns,omitempty"`
+	Status *GameMoveStatus `json:"status,omitempty"`
+	Winner string `json:"winner,omitempty"`
+	Players Players `json:"players,omitempty"`
+
+	// Position, sent with every message
+	FEN string `json:"fen"`
+	LastMove string `json:"lm,omitempty"`
+	// Remaining clock times in seconds
+	WhiteClock int `json:"wc,omitempty"`
+	BlackClock int `json:"bc,omitempty"`
+}
+
+// IsMove reports whether the message carries a move, rather than the game
+// description sent first and once the game is over.
+func (e GameMoveEvent) IsMove() bool {
+	return e.ID == ""
+}
+
+type GameMoveStatus struct {
+	ID int `json:"id"`
+	Name GameStatus `json:"name"`
+}
+
+// UnmarshalJSON also accepts the bare status name some endpoints send, such
+// as the "created" status of a new AI game.
+func (s *GameMoveStatus) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*s = GameMoveStatus{}
+		return json.Unmarshal(data, &s.Name)
+	}
+	return json.Unmarshal(data, (*gameMoveStatusFields)(s))
+}
+
+type gameMoveStatusFields GameMoveStatus
+
+func (*GameMoveStatus) jsonAlias() interface{} { return &gameMoveStatusFields{} }
+
+// OngoingGame is a game in progress of the authenticated user.
+type OngoingGame struct {
+	GameID string `json:"gameId"`
+	FullID string `json:"fullId"`
+	Color string `json:"color"`
+	FEN string `json:"fen"`
+	LastMove string `json:"lastMove"`
+	HasMoved bool `json:"hasMoved"`
+	IsMyTurn bool `json:"isMyTurn"`
+	Opponent OngoingOpponent `json:"opponent"`
+	Rated bool `json:"rated"`
+	Speed string `json:"speed"`
+	Perf string `json:"perf"`
+	Source string `json:"source"`
+	Variant Variant `json:"variant"`
+	// SecondsLeft is the time left on the user's clock, or to move in
+	// correspondence games
+	SecondsLeft int `json:"secondsLeft,omitempty"`
+}
+
+type OngoingOpponent struct {
+	ID string `json:"id"`
+	Username string `json:"username"`
+	Rating int `json:"rating,omitempty"`
+	// AI is the level of the Stockfish opponent, zero for humans
+	AI int `json:"ai,omitempty"`
+}
+
+// GetOngoingGames returns up to nb games of the authenticated user in
+// progress. Lichess returns at most 50 games, and 9 if nb is zero.
+func (l *Lichess) GetOngoingGames(ctx context.Context, nb int) ([]OngoingGame, error) {
+	values := url.Values{}
+	if nb > 0 {
+		values.Set("nb", strconv.Itoa(nb))
+	}
+	resp := struct {
+		NowPlaying []OngoingGame `json:"nowPlaying"`
+	}{}
+	err := l.getJSON(ctx, withQuery(nowPlayingPath, values), &resp)
+	return resp.NowPlaying, err
+}
+
+// StreamedGame is a message of a stream of games by ID, sent when a game
+// starts and again when it ends.
+type StreamedGame struct {
+	ID string `json:"id"`
+	Rated bool `json:"rated"`
+	Variant string `json:"variant"`
+	Speed string `json:"speed"`
+	Perf string `json:"perf"`
+	CreatedAt int64 `json:"createdAt"`
+	Status int `json:"status"`
+	StatusName string `json:"statusName"`
+	Winner string `json:"winner,omitempty"`
+	Players Players `json:"players"`
+}
+
+type LightUser struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+}
+
+type ExportFormat string
+
+const (
+	FormatPGN ExportFormat = "application/x-chess-pgn"
+	FormatNDJSON ExportFormat = "application/x-ndjson"
+)
+
+// ExportOptions are the output settings shared by the game export endpoints.
+// The zero value requests PGN with moves and tags, as Lichess does.
+type ExportOptions struct {
+	Format ExportFormat
+	NoMoves bool
+	NoTags bool
+	Clocks bool
+	Evals bool
+	Opening bool
+	Literate bool
+	// PGNInJSON includes the full PGN in each NDJSON game
+	PGNInJSON bool
+}
+
+func (o ExportOptions) accept() string {
+	if o.Format == "" {
+		return string(FormatPGN)
+	}
+	return string(o.Format)
+}
+
+func (o ExportOptions) values() url.Values {
+	v := url.Values{}
+	if o.NoMoves {
+		v.Set("moves", "false")
+	}
+	if o.NoTags {
+		v.Set("tags", "false")
+	}
+	if o.Clocks {
+		v.Set("clocks", "true")
+	}
+	if o.Evals {
+		v.Set("evals", "true")
+	}
+	if o.Opening {
+		v.Set("opening", "true")
+	}
+	if o.Literate {
+		v.Set("literate", "true")
+	}
+	if o.PGNInJSON {
+		v.Set("pgnInJson", "true")
+	}
+	return v
+}
+
+// UserGamesOptions filters the games exported for a user. Zero values apply
+// no filter.
+type UserGamesOptions struct {
+	ExportOptions
+	Since time.Time
+	Until time.Time
+	Max int
+	Vs string
+	// Rated restricts the export to rated (true) or casual (false) games
+	Rated *bool
+	PerfType []string
+	Color string
+	Analysed *bool
+	Ongoing bool
+}
+
+func (o UserGamesOptions) values() url.Values {
+	v := o.ExportOptions.values()
+	if !o.Since.IsZero() {
+		v.Set("since", strconv.FormatInt(o.Since.UnixMilli(), 10))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", strconv.FormatInt(o.Until.UnixMilli(), 10))
+	}
+	if o.Max > 0 {
+		v.Set("max", strconv.Itoa(o.Max))
+	}
+	if o.Vs != "" {
+		v.Set("vs", o.Vs)
+	}
+	if o.Rated != nil {
+		v.Set("rated", strconv.FormatBool(*o.Rated))
+	}
+	if len(o.PerfType) > 0 {
+		v.Set("perfType", strings.Join(o.PerfType, ","))
+	}
+	if o.Color != "" {
+		v.Set("color", o.Color)
+	}
+	if o.Analysed != nil {
+		v.Set("analysed", strconv.FormatBool(*o.Analysed))
+	}
+	if o.Ongoing {
+		v.Set("ongoing", "true")
+	}
+	return v
+}
+
+func withQuery(path string, v url.Values) string {
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// ExportGameTo writes a single game to w in the requested format.
+func (l *Lichess) ExportGameTo(ctx context.Context, gameID string, opts ExportOptions, w io.Writer) (int64, error) {
+	path := withQuery(fmt.Sprintf(exportGamePath, url.PathEscape(gameID)), opts.values())
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", opts.accept())
+	return l.copyTo(req, w)
+}
+
+// ExportUserGamesTo copies the games of username straight into w as they are
+// streamed by Lichess, so archives of any size can be written to disk
+// without being held in memory.
+func (l *Lichess) ExportUserGamesTo(ctx context.Context, username string, opts UserGamesOptions, w io.Writer) (int64, error) {
+	path := withQuery(fmt.Sprintf(exportUserGamesPath, url.PathEscape(username)), opts.values())
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", opts.accept())
+	return l.copyTo(req, w)
+}
+
+// GetCurrentGameOf returns the ongoing game of username, or the last game
+// played if none is ongoing. Format is ignored, the game is always decoded
+// from JSON.
+func (l *Lichess) GetCurrentGameOf(ctx context.Context, username string, opts ExportOptions) (GameSummary, error) {
+	path := withQuery(fmt.Sprintf(currentGamePath, url.PathEscape(username)), opts.values())
+	game := GameSummary{}
+	err := l.getJSON(ctx, path, &game)
+	return game, err
+}
+
+// ExportUserGames streams the games of username one at a time as Lichess
+// sends them, most recent first, so that any number of them can be processed
+// without being held in memory. The games are always requested as NDJSON,
+// set opts.PGNInJSON to get the PGN of each game along with it.
+func (l *Lichess) ExportUserGames(ctx context.Context, username string, opts UserGamesOptions) *Stream[GameSummary] {
+	path := withQuery(fmt.Sprintf(exportUserGamesPath, url.PathEscape(username)), opts.values())
+	return newStream(ctx, l, streamSpec[GameSummary]{path: path})
+}
+
+// ExportGamesByIDs streams the games of ids, up to MaxGameIDs of them, in the
+// order they are exported by Lichess.
+func (l *Lichess) ExportGamesByIDs(ctx context.Context, ids []string, opts ExportOptions) *Stream[GameSummary] {
+	if len(ids) > MaxGameIDs {
+		return failedStream[GameSummary](fmt.Errorf("lichess: at most %d games can be exported at once, got %d", MaxGameIDs, len(ids)))
+	}
+	path := withQuery(exportGamesByIDsPath, opts.values())
+	body := strings.Join(ids, ",")
+	return newStream(ctx, l, streamSpec[GameSummary]{path: path, body: &body})
+}
+
+// StreamGamesByIDs follows the games of ids, sending each of them once when
+// the stream opens or the game starts, and again when it ends. streamID is
+// chosen by the caller and names the stream for AddGamesToStream, ids may
+// be empty to only follow games added later.
+func (l *Lichess) StreamGamesByIDs(ctx context.Context, streamID string, ids []string) *Stream[StreamedGame] {
+	if len(ids) > MaxGameIDs {
+		return failedStream[StreamedGame](fmt.Errorf("lichess: at most %d games can be streamed at once, got %d", MaxGameIDs, len(ids)))
+	}
+	path := fmt.Sprintf(streamGamesByIDsPath, url.PathEscape(streamID))
+	body := strings.Join(ids, ",")
+	return newStream(ctx, l, streamSpec[StreamedGame]{path: path, body: &body})
+}
+
+// AddGamesToStream makes the open stream streamID follow ids as well.
+func (l *Lichess) AddGamesToStream(ctx context.Context, streamID string, ids []string) error {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	path := fmt.Sprintf(addGamesToStreamPath, url.PathEscape(streamID))
+	body := strings.Join(ids, ",")
+	req, err := l.newStreamRequest(ctx, path, &body)
+	if err != nil {
+		return err
+	}
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ImportedGame is a game created on Lichess from a PGN.
+type ImportedGame struct {
+	ID string `json:"id"`
+	URL string `json:"url"`
+}
+
+// ImportGame uploads a game played elsewhere, such as over the board, so it
+// can be analysed on Lichess. Imported games are attributed to the
+// authenticated user, if any.
+func (l *Lichess) ImportGame(ctx context.Context, pgn string) (ImportedGame, error) {
+	game := ImportedGame{}
+	err := l.postFormJSON(ctx, importGamePath, url.Values{"pgn": {pgn}}, &game)
+	return game, err
+}
+
+// StreamGameMoves sends the moves of any ongoing game as they are played,
+// no scope is required. It returns once the game is over or ctx is done.
+func (l *Lichess) StreamGameMoves(ctx context.Context, gameID string, events chan<- GameMoveEvent) error {
+	return runStream(ctx, l, gameMovesSpec(gameID), events)
+}
+
+// GameMoveStream follows any ongoing game like StreamGameMoves.
+func (l *Lichess) GameMoveStream(ctx context.Context, gameID string) *Stream[GameMoveEvent] {
+	return newStream(ctx, l, gameMovesSpec(gameID))
+}
+
+func gameMovesSpec(gameID string) streamSpec[GameMoveEvent] {
+	return streamSpec[GameMoveEvent]{path: fmt.Sprintf(streamGameMovesPath, url.PathEscape(gameID))}
+}
@@ -0,0 +1,90 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+)
+
+/*
+ * EVENT HANDLERS
+ */
+
+// Decision is the answer of an EventHandler to a challenge.
+type Decision int
+
+const (
+	// DecisionIgnore leaves the challenge pending
+	DecisionIgnore Decision = iota
+	DecisionAccept
+	DecisionDecline
+)
+
+// EventHandler holds the callbacks run for the events of the user's event
+// stream, nil callbacks are skipped.
+type EventHandler struct {
+	// RatingRange declines the challenges of players rated outside of it
+	// before OnChallenge is asked, the zero value accepting any rating
+	RatingRange RatingRange
+	OnChallenge func(Challenge) Decision
+	OnGameStart func(Game)
+	OnGameFinish func(Game)
+	// OnError is told about challenges that could not be answered
+	OnError func(error)
+}
+
+// RegisterHandler runs h for every event of the user's event stream, in a
+// goroutine of its own, until ctx is done. Callbacks are called one at a time
+// and hold up the following events while they run. The returned channel
+// receives the error that ended the stream, if any, and is closed after. An
+// invalid h.RatingRange is reported there without opening the stream.
+func (l *Lichess) RegisterHandler(ctx context.Context, h EventHandler) <-chan error {
+	errs := make(chan error, 1)
+	if err := h.RatingRange.Validate(); err != nil {
+		errs <- err
+		close(errs)
+		return errs
+	}
+	go func() {
+		defer close(errs)
+		stream := l.EventStream(ctx)
+		defer stream.Close()
+		for event := range stream.Events() {
+			l.dispatch(ctx, h, event)
+		}
+		if err := <-stream.Errors(); err != nil {
+			errs <- err
+		}
+	}()
+	return errs
+}
+
+func (l *Lichess) dispatch(ctx context.Context, h EventHandler, event Event) {
+	switch event.Type {
+	case "challenge":
+		decision := DecisionIgnore
+		switch {
+		case event.Challenge.Direction != "out" && !h.RatingRange.Contains(event.Challenge.Challenger.Rating):
+			decision = DecisionDecline
+		case h.OnChallenge != nil:
+			decision = h.OnChallenge(event.Challenge)
+		}
+		var err error
+		switch decision {
+		case DecisionAccept:
+			err = l.AcceptChallenge(ctx, event.Challenge.ID)
+		case DecisionDecline:
+			err = l.DeclineChallenge(ctx, event.Challenge.ID, DeclineGeneric)
+		}
+		if err != nil && h.OnError != nil && ctx.Err() == nil {
+			h.OnError(fmt.Errorf("challenge %s: %w", event.Challenge.ID, err))
+		}
+	case "gameStart":
+		if h.OnGameStart != nil {
+			h.OnGameStart(event.Game)
+		}
+	case "gameFinish":
+		if h.OnGameFinish != nil {
+			h.OnGameFinish(event.Game)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package lichess_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hmccarty/lichess"
+	"github.com/hmccarty/lichess/lichesstest"
+)
+
+func TestHandlerRatingRange(t *testing.T) {
+	srv := lichesstest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	asked := make(chan string, 2)
+	srv.Client().RegisterHandler(ctx, lichess.EventHandler{
+		RatingRange: lichess.RatingRange{Min: 1500, Max: 2000},
+		OnChallenge: func(c lichess.Challenge) lichess.Decision {
+			asked <- c.ID
+			return lichess.DecisionAccept
+		},
+	})
+	low := srv.AddChallenge(lichess.Challenge{Challenger: lichess.Challenger{ID: "low", Rating: 1200}})
+	inRange := srv.AddChallenge(lichess.Challenge{Challenger: lichess.Challenger{ID: "mid", Rating: 1800}})
+
+	if id := <-asked; id != inRange.ID {
+		t.Errorf("OnChallenge asked about %s, want %s", id, inRange.ID)
+	}
+	for _, want := range []struct {
+		id string
+		status string
+	}{{low.ID, "declined"}, {inRange.ID, "accepted"}} {
+		for {
+			if c, _ := srv.Challenge(want.id); c.Status == want.status {
+				break
+			} else if ctx.Err() != nil {
+				t.Fatalf("challenge %s is %s, want %s", want.id, c.Status, want.status)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestHandlerInvalidRatingRange(t *testing.T) {
+	srv := lichesstest.NewServer()
+	defer srv.Close()
+	errs := srv.Client().RegisterHandler(context.Background(), lichess.EventHandler{
+		RatingRange: lichess.RatingRange{Min: 2000, Max: 1500},
+	})
+	if err := <-errs; err == nil {
+		t.Error("invalid rating range accepted")
+	}
+}
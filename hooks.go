@@ -0,0 +1,104 @@
+package lichess
+
+import (
+	"net/http"
+	"time"
+)
+
+/*
+ * HOOKS
+ */
+
+// Hooks are called on the traffic of the client, e.g. to export metrics.
+// Any of them may be nil. They are called synchronously, from the goroutine
+// making the request, and must not block.
+type Hooks struct {
+	// OnRequest is called before each attempt of a request is sent
+	OnRequest func(RequestInfo)
+	// OnResponse is called once the response of an attempt is received, or
+	// the attempt failed. For streams it is called as soon as the headers
+	// are received.
+	OnResponse func(ResponseInfo)
+	// OnStreamReconnect is called before a dropped stream is reopened
+	OnStreamReconnect func(ReconnectInfo)
+	// OnRateLimited is called for each 429 response
+	OnRateLimited func(RateLimitInfo)
+}
+
+type RequestInfo struct {
+	Method string
+	// Path is the path of the URL, without the query
+	Path string
+	Host string
+}
+
+type ResponseInfo struct {
+	RequestInfo
+	// StatusCode is zero when no response was received
+	StatusCode int
+	// Duration is the time from sending the request to receiving the
+	// response headers
+	Duration time.Duration
+	// Err is the error of the attempt, if any, including the errors
+	// returned for non 2xx responses
+	Err error
+}
+
+type ReconnectInfo struct {
+	Path string
+	// Attempt counts the reconnections since the last value received, from 0
+	Attempt int
+	Delay time.Duration
+	// Err is what ended the connection
+	Err error
+}
+
+type RateLimitInfo struct {
+	RequestInfo
+	RetryAfter time.Duration
+	// Retrying is false when the error is returned to the caller
+	Retrying bool
+}
+
+func WithHooks(hooks Hooks) ClientOption {
+	return func(l *Lichess) {
+		l.hooks = hooks
+	}
+}
+
+func (l *Lichess) SetHooks(hooks Hooks) {
+	l.hooks = hooks
+}
+
+func requestInfo(req *http.Request) RequestInfo {
+	return RequestInfo{Method: req.Method, Path: req.URL.Path, Host: req.URL.Host}
+}
+
+func (h Hooks) request(req *http.Request) {
+	if h.OnRequest != nil {
+		h.OnRequest(requestInfo(req))
+	}
+}
+
+func (h Hooks) response(req *http.Request, resp *http.Response, start time.Time, err error) {
+	if h.OnResponse == nil {
+		return
+	}
+	info := ResponseInfo{RequestInfo: requestInfo(req), Duration: time.Since(start), Err: err}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	h.OnResponse(info)
+}
+
+func (h Hooks) streamReconnect(path string, attempt int, delay time.Duration, err error) {
+	if h.OnStreamReconnect != nil {
+		h.OnStreamReconnect(ReconnectInfo{Path: path, Attempt: attempt, Delay: delay, Err: err})
+	}
+}
+
+func (h Hooks) rateLimited(req *http.Request, retryAfter time.Duration, retrying bool) {
+	if h.OnRateLimited != nil {
+		h.OnRateLimited(RateLimitInfo{RequestInfo: requestInfo(req), RetryAfter: retryAfter, Retrying: retrying})
+	}
+}
@@ -0,0 +1,60 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+/*
+ * LEADERBOARDS
+ */
+
+// GET
+const top10Path = "/api/player"
+const leaderboardPath = "/api/player/top/%d/%s" // Nb, PerfType
+
+// Leaderboards are at most this long.
+const MaxLeaderboard = 200
+
+// LeaderboardEntry is a player of a leaderboard, Perfs only holds the perfs
+// the player is ranked in.
+type LeaderboardEntry struct {
+	ID string `json:"id"`
+	Username string `json:"username"`
+	Title string `json:"title,omitempty"`
+	Online bool `json:"online,omitempty"`
+	Patron bool `json:"patron,omitempty"`
+	Perfs map[string]LeaderboardPerf `json:"perfs"`
+}
+
+type LeaderboardPerf struct {
+	Rating int `json:"rating"`
+	// Progress is the rating change over the last 12 games
+	Progress int `json:"progress"`
+}
+
+// Rating returns the rating of the player in perf, e.g. "blitz".
+func (e LeaderboardEntry) Rating(perf string) int {
+	return e.Perfs[perf].Rating
+}
+
+// GetAllTop10 returns the 10 best players of every perf, by perf key.
+func (l *Lichess) GetAllTop10(ctx context.Context) (map[string][]LeaderboardEntry, error) {
+	top := map[string][]LeaderboardEntry{}
+	err := l.getJSON(ctx, top10Path, &top)
+	return top, err
+}
+
+// GetLeaderboard returns the nb best players of perfType, up to
+// MaxLeaderboard, best first.
+func (l *Lichess) GetLeaderboard(ctx context.Context, perfType string, nb int) ([]LeaderboardEntry, error) {
+	if nb <= 0 || nb > MaxLeaderboard {
+		return nil, fmt.Errorf("lichess: leaderboard size must be between 1 and %d, got %d", MaxLeaderboard, nb)
+	}
+	resp := struct {
+		Users []LeaderboardEntry `json:"users"`
+	}{}
+	err := l.getJSON(ctx, fmt.Sprintf(leaderboardPath, nb, url.PathEscape(perfType)), &resp)
+	return resp.Users, err
+}
@@ -1,33 +1,69 @@
 package lichess
 
 import (
-	"os"
-	"log"
-	"sync"
 	"fmt"
-	"bufio"
-	"strings"
 	"io"
+	"time"
+	"strconv"
+	"sync"
+	"context"
+	"net/url"
+	"net/http"
 	"encoding/json"
 	"golang.org/x/oauth2"
 )
 
 const lichessURL = "https://lichess.org"
 
+// Lichess is a client of the Lichess API, it must not be copied once used.
+// It is safe for concurrent use: authentication, the cached account and the
+// registry of games are guarded, and may change while requests are in
+// flight. The configuration (the ClientOptions and the Set methods) is meant
+// to be set up before the client is shared between goroutines.
 type Lichess struct {
+	// mu guards client, account, games and current
+	mu sync.RWMutex
 	client *AuthorizedClient
-	profile Profile
-	currGame Game
+	account *Profile
+	// games holds the games followed by a GameSession, by game ID,
+	// current being the last one started
+	games map[string]Game
+	current string
+	maintenanceBackoff []time.Duration
+	maxBodySize int64
+	maxLineSize int
+	streamIdleTimeout time.Duration
+	opponentLookup bool
+	onGameEnd func(GameSummary)
+
+	// set by the ClientOptions of NewLichess
+	baseClient *http.Client
+	baseURL string
+	userAgent string
+	timeout time.Duration
+	limits *rateLimitState
+	rateLimitRetries int
+	logger Logger
+	hooks Hooks
+	cache *ResponseCache
+	// strict reports unknown fields, see SetStrictDecoding
+	strict bool
+	// bot plays through the Bot API, see SetBot
+	bot bool
 }
 
 /*
  * ACCOUNTS
  */
 
+// GET
 const accountPath = "/api/account"
 const emailPath = "/api/account/email"
 const prefPath = "/api/account/preferences"
-const kidModePath = "api/account/kid"
+const kidModePath = "/api/account/kid" // also POSTed to
+
+// POST
+const setPrefPath = "/account/preferences/%s" // Preference key
 
 type Profile struct {
 	ID string `json:"id"`
@@ -36,8 +72,9 @@ type Profile struct {
 	Online bool `json:"online"`
 	Playing bool `json:"playing"`
 	Streaming bool `json:"streaming"`
-	CreatedAt uint64 `json:"createdAt"`
-	SeenAt uint64 `json:"seenAt"`
+	// CreatedAt and SeenAt are sent in milliseconds since the epoch
+	CreatedAt time.Time `json:"-"`
+	SeenAt time.Time `json:"-"`
 	Details Details `json:"profile"`
 	NbFollowers uint32 `json:"nbFollowers"`
 	NbFollowing uint32 `json:"nbFollowing"`
@@ -52,13 +89,47 @@ type Profile struct {
 	PlayTime PlayTime `json:"playTime"`
 }
 
+// profileTimes are the dates of a profile as sent by Lichess.
+type profileTimes struct {
+	CreatedAt int64 `json:"createdAt"`
+	SeenAt int64 `json:"seenAt"`
+}
+
+// profileJSON is a Profile as sent by Lichess.
+type profileJSON struct {
+	profileFields
+	profileTimes
+}
+
+type profileFields Profile
+
+func (*Profile) jsonAlias() interface{} { return &profileJSON{} }
+
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	var v profileJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Profile(v.profileFields)
+	p.CreatedAt, p.SeenAt = millisTime(v.profileTimes.CreatedAt), millisTime(v.profileTimes.SeenAt)
+	return nil
+}
+
+func (p Profile) MarshalJSON() ([]byte, error) {
+	type profile Profile
+	return json.Marshal(struct {
+		profile
+		profileTimes
+	}{profile(p), profileTimes{timeMillis(p.CreatedAt), timeMillis(p.SeenAt)}})
+}
+
 type Details struct {
 	Bio string `json:"bio"`
-	Country string `json:country"`
-	FirstName string `json:firstName"`
-	LastName string `json:lastName"`
-	Links string `json:links"`
-	Location string `json:location"`
+	Country string `json:"country"`
+	FirstName string `json:"firstName"`
+	LastName string `json:"lastName"`
+	Links string `json:"links"`
+	Location string `json:"location"`
 }
 
 type Count struct {
@@ -70,7 +141,7 @@ type Count struct {
 	Import uint32 `json:"import"`
 	Loss uint32 `json:"loss"`
 	LossH uint32 `json:"lossH"`
-	me uint32 `json:"me"`
+	Me uint32 `json:"me"`
 	Playing uint32 `json:"playing"`
 	Rated uint32 `json:"rated"`
 	Win uint32 `json:"win"`
@@ -78,12 +149,60 @@ type Count struct {
 }
 
 type Performance struct {
-	Blitz PerfType `json:"blitz"`
+	UltraBullet PerfType `json:"ultraBullet"`
 	Bullet PerfType `json:"bullet"`
+	Blitz PerfType `json:"blitz"`
+	Rapid PerfType `json:"rapid"`
+	Classical PerfType `json:"classical"`
+	Correspondence PerfType `json:"correspondence"`
 	Chess960 PerfType `json:"chess960"`
+	Crazyhouse PerfType `json:"crazyhouse"`
+	Antichess PerfType `json:"antichess"`
+	Atomic PerfType `json:"atomic"`
+	Horde PerfType `json:"horde"`
+	KingOfTheHill PerfType `json:"kingOfTheHill"`
+	RacingKings PerfType `json:"racingKings"`
+	ThreeCheck PerfType `json:"threeCheck"`
 	Puzzle PerfType `json:"puzzle"`
 }
 
+// ByKey returns the rating of a perf by its Lichess key, e.g. "blitz".
+func (p Performance) ByKey(key string) (PerfType, bool) {
+	switch key {
+	case "ultraBullet":
+		return p.UltraBullet, true
+	case "bullet":
+		return p.Bullet, true
+	case "blitz":
+		return p.Blitz, true
+	case "rapid":
+		return p.Rapid, true
+	case "classical":
+		return p.Classical, true
+	case "correspondence":
+		return p.Correspondence, true
+	case "chess960":
+		return p.Chess960, true
+	case "crazyhouse":
+		return p.Crazyhouse, true
+	case "antichess":
+		return p.Antichess, true
+	case "atomic":
+		return p.Atomic, true
+	case "horde":
+		return p.Horde, true
+	case "kingOfTheHill":
+		return p.KingOfTheHill, true
+	case "racingKings":
+		return p.RacingKings, true
+	case "threeCheck":
+		return p.ThreeCheck, true
+	case "puzzle":
+		return p.Puzzle, true
+	}
+	return PerfType{}, false
+}
+
 type PerfType struct {
 	Games uint32 `json:"games"`
 	Progress int16 `json:"prog"`
@@ -91,44 +210,74 @@ type PerfType struct {
 	Rd uint16 `json:"rd"`
 }
 
+// PlayTime is the time spent playing, and on Lichess TV. It is sent in
+// seconds.
 type PlayTime struct {
-	Total uint64 `json:"total"`
-	Tv uint64 `json:"tv"`
+	Total time.Duration
+	Tv time.Duration
+}
+
+type playTime struct {
+	Total int64 `json:"total"`
+	Tv int64 `json:"tv"`
+}
+
+func (*PlayTime) jsonAlias() interface{} { return &playTime{} }
+
+func (p *PlayTime) UnmarshalJSON(data []byte) error {
+	var v playTime
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = PlayTime{time.Duration(v.Total) * time.Second, time.Duration(v.Tv) * time.Second}
+	return nil
+}
+
+func (p PlayTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(playTime{int64(p.Total / time.Second), int64(p.Tv / time.Second)})
 }
 
+// Preferences are the settings of the account, as returned by the
+// preferences endpoint. The numeric modes are typed, see preferences.go.
 type Preferences struct {
 	DarkMode bool `json:"dark"`
 	TranspMode bool `json:"transp"`
 	BgImg string `json:"bgImg"`
 	Is3D bool `json:"is3d"`
 	Theme string `json:"theme"`
-	PieceSet string `json:"go"`
+	PieceSet string `json:"pieceSet"`
 	Theme3D string `json:"theme3d"`
 	PieceSet3D string `json:"pieceSet3d"`
 	SoundSet string `json:"soundSet"`
-	BlindFold uint8 `json:"blindFold"`
-	AutoQueen uint8 `json:"autoQueen"`
-	AutoThreeFold uint8 `json:"autoThreefold"`
-	Takeback uint8 `json:"takeback"`
-	ClockTenths uint8 `json:"clockTenths"`
+	BlindFold uint8 `json:"blindfold"`
+	AutoQueen AutoQueen `json:"autoQueen"`
+	AutoThreeFold AutoThreefold `json:"autoThreefold"`
+	Takeback Takeback `json:"takeback"`
+	MoreTime Takeback `json:"moretime"`
+	ClockTenths ClockTenths `json:"clockTenths"`
 	ClockBar bool `json:"clockBar"`
+	ClockSound bool `json:"clockSound"`
 	Premove bool `json:"premove"`
-	Animation uint8 `json:"animation"` 	
-	Captured bool `json:"captured"`	
-	Follow bool `json:"follow"`	
-	Highlight bool `json:"highlight"`	
-	Destination bool `json:"destination"`	
-	Coords uint8 `json:"coords"`	
-	Replay uint8 `json:"replay"`	
-	Challenge uint8 `json:"challenge"`	
-	Message uint8 `json:"message"`	
-	CoordColor uint8 `json:"coordColor"`	
-	SubmitMove uint8 `json:"submitMove"`	
-	ConfirmResign uint8`json:"confirmResign"` 	
-	InsightShare uint8 `json:"insightShare"`	
-	KeyboardMove uint8 `json:"keyboardMove"`	
-	Zen uint8 `json:"zen"`	
-	MoveEvent uint8 `json:"moveEvent"`	
+	Animation Animation `json:"animation"`
+	Captured bool `json:"captured"`
+	Follow bool `json:"follow"`
+	Highlight bool `json:"highlight"`
+	Destination bool `json:"destination"`
+	Coords Coords `json:"coords"`
+	Replay Replay `json:"replay"`
+	Challenge ChallengePref `json:"challenge"`
+	Message MessagePref `json:"message"`
+	CoordColor uint8 `json:"coordColor"`
+	SubmitMove SubmitMove `json:"submitMove"`
+	ConfirmResign uint8 `json:"confirmResign"`
+	InsightShare InsightShare `json:"insightShare"`
+	KeyboardMove uint8 `json:"keyboardMove"`
+	Zen uint8 `json:"zen"`
+	Ratings uint8 `json:"ratings"`
+	MoveEvent MoveEvent `json:"moveEvent"`
+	RookCastle uint8 `json:"rookCastle"`
+	// Language is sent next to the preferences, e.g. "en-GB"
+	Language string `json:"language,omitempty"`
 }
 
 /*
@@ -138,6 +287,7 @@ type Preferences struct {
 // GET
 const streamEventPath = "/api/stream/event"
 const streamBoardPath = "/api/board/game/stream/%s" // GameID
+const chatPath = "/api/board/game/%s/chat" // GameID
 
 // POST
 const seekPath = "/api/board/seek"
@@ -146,6 +296,9 @@ const sendChatPath = "/api/board/game/%s/chat" // GameID
 const abortGamePath = "/api/board/game/%s/abort" // GameID
 const resignGamePath = "/api/board/game/%s/resign" // GameID
 const drawGamePath = "/api/board/game/%s/draw/%s" // GameID, Decision
+const takebackGamePath = "/api/board/game/%s/takeback/%s" // GameID, Decision
+const claimVictoryPath = "/api/board/game/%s/claim-victory" // GameID
+const berserkPath = "/api/board/game/%s/berserk" // GameID
 
 type Event struct {
 	Type string `json:"type"`
@@ -155,11 +308,44 @@ type Event struct {
 
 type Challenge struct {
 	ID string `json:"id"`
-	Status string `json:"created"`
+	URL string `json:"url,omitempty"`
+	Status string `json:"status"`
 	Challenger Challenger `json:"challenger"`
 	Variant Variant `json:"variant"`
 	Rated bool `json:"rated"`
-	Color string `json:"color"`
+	Color Color `json:"color"`
+	TimeControl ChallengeTimeControl `json:"timeControl"`
+	Speed Speed `json:"speed"`
+	// DestUser is the challenged player, nil for open challenges
+	DestUser *Challenger `json:"destUser"`
+	// InitialFen is set for challenges from a custom position
+	InitialFen string `json:"initialFen,omitempty"`
+	// Direction is "in" for the challenges received by the user and "out"
+	// for the ones they sent, only set on the event stream and listings
+	Direction string `json:"direction,omitempty"`
+}
+
+// ChallengeTimeControl is the time control of a challenge, Type being
+// "clock", "correspondence" or "unlimited". Limit and Increment are in
+// seconds.
+type ChallengeTimeControl struct {
+	Type string `json:"type"`
+	Limit int `json:"limit,omitempty"`
+	Increment int `json:"increment,omitempty"`
+	// Show is the clock as displayed, e.g. "5+3"
+	Show string `json:"show,omitempty"`
+	DaysPerTurn int `json:"daysPerTurn,omitempty"`
+}
+
+// Clock returns the clock of a "clock" time control, nil otherwise.
+func (t ChallengeTimeControl) Clock() *Clock {
+	if t.Type != "clock" {
+		return nil
+	}
+	return &Clock{
+		Initial: time.Duration(t.Limit) * time.Second,
+		Increment: time.Duration(t.Increment) * time.Second,
+	}
 }
 
 type Challenger struct {
@@ -173,65 +359,336 @@ type Challenger struct {
 }
 
 type Variant struct {
-	Key string `json:"key"`
+	Key VariantKey `json:"key"`
 	Name string `json:"name"`
 	Short string `json:"short"`
 }
 
+// UnmarshalJSON also accepts the bare variant key some endpoints send, such
+// as the tournament details.
+func (v *Variant) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*v = Variant{}
+		return json.Unmarshal(data, &v.Key)
+	}
+	return json.Unmarshal(data, (*variantFields)(v))
+}
+
+type variantFields Variant
+
+func (*Variant) jsonAlias() interface{} { return &variantFields{} }
+
+// Clock is the time control of a board API game, sent in milliseconds.
 type Clock struct {
-	Initial uint32 `json:"initial"`
-	Increment uint32 `json:"increment"`
+	Initial time.Duration
+	Increment time.Duration
+}
+
+type clockMillis struct {
+	Initial int64 `json:"initial"`
+	Increment int64 `json:"increment"`
+}
+
+func (*Clock) jsonAlias() interface{} { return &clockMillis{} }
+
+func (c *Clock) UnmarshalJSON(data []byte) error {
+	var v clockMillis
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Clock{Millis(v.Initial), Millis(v.Increment)}
+	return nil
+}
+
+func (c Clock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(clockMillis{c.Initial.Milliseconds(), c.Increment.Milliseconds()})
 }
 
 type Game struct {
 	ID string `json:"id"`
-	Board chan Board
+	FullID string `json:"fullId,omitempty"`
+	Color Color `json:"color,omitempty"`
+	FEN string `json:"fen,omitempty"`
+	IsMyTurn bool `json:"isMyTurn,omitempty"`
+	LastMove string `json:"lastMove,omitempty"`
+	Opponent Opponent `json:"opponent,omitempty"`
+	Perf string `json:"perf,omitempty"`
+	Rated bool `json:"rated,omitempty"`
+	Speed Speed `json:"speed,omitempty"`
+	Variant Variant `json:"variant,omitempty"`
+	SecondsLeft int `json:"secondsLeft,omitempty"`
+	Source string `json:"source,omitempty"`
+	// Set on gameFinish events
+	Status *GameMoveStatus `json:"status,omitempty"`
+	Winner Color `json:"winner,omitempty"`
+	RatingDiff *int `json:"ratingDiff,omitempty"`
+	// OpponentProfile is fetched on gameStart when opponent lookup is enabled
+	OpponentProfile *Profile `json:"-"`
+	Board chan BoardEvent `json:"-"`
+	// Position follows the board of games followed by a GameSession
+	Position *PositionTracker `json:"-"`
+	// lichess is the client the game was received with
+	lichess *Lichess
 }
 
-type Board struct {
-	Type string `json:"type"`
+type Opponent struct {
+	ID string `json:"id"`
+	Username string `json:"username"`
+	Rating int `json:"rating"`
+	// AI is the Stockfish level when playing against the computer
+	AI int `json:"ai,omitempty"`
+}
 
-	// Game Full
-	ID string `json:"id", omitempty`
-	Rated bool `json:"rated, omitempty"`
-	Variant Variant `json:"variant, omitempty"`
-	Clock Clock `json:"clock, omitempty"`
-	Speed string `json:"speed, omitempty"`
-	CreatedAt uint32 `json:"createdAt, omitempty"`
-	White WhiteSide `json:"white,omitempty"`
-	Black BlackSide `json:"black,omitempty"`
-	InitialFen string `json:"initialFen, omitempty"`
-	State State `json:"state, omitempty"`
-
-	// Game State
-	Moves string `json:"moves,omitempty"`
-	Status string `json:"status,omitempty"`
-	Winner string `json:"winner,omitempty"`
-
-	// Chat Line
-	Username string `json:"username, omitempty"`	
-	Text string `json:"text, omitempty"`
-	Room string `json:"room, omitempty"`
-}
-
-type State struct {
-	Type string `json:"gameState"`
-	Moves string `json:"moves"`
-	WhiteTime uint32 `json:"wtime"`
-	BlackTime uint32 `json:"btime"`
-	WhiteIncre uint32 `json:"winc"`
-	BlackIncre uint32 `json:"binc"`
-	Status string `json:"status"`
+// OpponentPerf returns the opponent's rating details in the perf of the game,
+// it requires OpponentProfile to be set.
+func (g Game) OpponentPerf() (PerfType, bool) {
+	if g.OpponentProfile == nil {
+		return PerfType{}, false
+	}
+	perf := g.Perf
+	if perf == "" {
+		perf = string(g.Speed)
+	}
+	return g.OpponentProfile.Performance.ByKey(perf)
 }
 
-type WhiteSide struct {
-	ID string `json:"id"`
-	Name string `json:"name"`
+// BoardEvent is a message of the board stream of a game: a GameFull, a
+// GameState, a ChatLine, an OpponentGone or a TakebackOffer.
+type BoardEvent interface {
+	// EventType returns the type of the event as named by Lichess, e.g.
+	// "gameFull"
+	EventType() string
 }
 
-type BlackSide struct {
+// GameFull is the first event of a board stream, and the first again after
+// every reconnection.
+type GameFull struct {
 	ID string `json:"id"`
-	Name string `json:"name"`
+	Rated bool `json:"rated"`
+	Variant Variant `json:"variant"`
+	Clock Clock `json:"clock"`
+	Speed Speed `json:"speed"`
+	// CreatedAt is sent in milliseconds since the epoch
+	CreatedAt time.Time `json:"-"`
+	White Player `json:"white"`
+	Black Player `json:"black"`
+	InitialFen string `json:"initialFen"`
+	// State is the state of the game when the stream started, its
+	// NewMoves are filled in like the ones of later states
+	State GameState `json:"state"`
+}
+
+// gameFullJSON is a GameFull as sent by Lichess.
+type gameFullJSON struct {
+	gameFullFields
+	CreatedAt int64 `json:"createdAt"`
+}
+
+type gameFullFields GameFull
+
+func (*GameFull) jsonAlias() interface{} { return &gameFullJSON{} }
+
+func (g *GameFull) UnmarshalJSON(data []byte) error {
+	var v gameFullJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*g = GameFull(v.gameFullFields)
+	g.CreatedAt = millisTime(v.CreatedAt)
+	return nil
+}
+
+func (g GameFull) MarshalJSON() ([]byte, error) {
+	type gameFull GameFull
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		gameFull
+		CreatedAt int64 `json:"createdAt"`
+	}{g.EventType(), gameFull(g), timeMillis(g.CreatedAt)})
+}
+
+// GameState is sent whenever a move is played, a draw or takeback is
+// proposed, or the game ends.
+type GameState struct {
+	Moves string `json:"moves"`
+	// The clocks are sent in milliseconds
+	WhiteTime time.Duration `json:"-"`
+	BlackTime time.Duration `json:"-"`
+	WhiteIncre time.Duration `json:"-"`
+	BlackIncre time.Duration `json:"-"`
+	Status GameStatus `json:"status"`
+	Winner Color `json:"winner,omitempty"`
+	// Pending draw and takeback proposals
+	WhiteDraw bool `json:"wdraw,omitempty"`
+	BlackDraw bool `json:"bdraw,omitempty"`
+	WhiteTakeback bool `json:"wtakeback,omitempty"`
+	BlackTakeback bool `json:"btakeback,omitempty"`
+	// Set once the player went berserk in an arena game
+	WhiteBerserk bool `json:"wberserk,omitempty"`
+	BlackBerserk bool `json:"bberserk,omitempty"`
+
+	// NewMoves holds the moves this event added since the previous one, it is
+	// filled in by WatchForBoardUpdates
+	NewMoves []string `json:"-"`
+}
+
+type stateClocks struct {
+	WhiteTime int64 `json:"wtime"`
+	BlackTime int64 `json:"btime"`
+	WhiteIncre int64 `json:"winc"`
+	BlackIncre int64 `json:"binc"`
+}
+
+// gameStateJSON is a GameState as sent by Lichess.
+type gameStateJSON struct {
+	gameStateFields
+	stateClocks
+	// Type is also sent for the state nested in a gameFull
+	Type string `json:"type"`
+}
+
+type gameStateFields GameState
+
+func (*GameState) jsonAlias() interface{} { return &gameStateJSON{} }
+
+func (s *GameState) UnmarshalJSON(data []byte) error {
+	var v gameStateJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = GameState(v.gameStateFields)
+	s.WhiteTime, s.BlackTime = Millis(v.stateClocks.WhiteTime), Millis(v.stateClocks.BlackTime)
+	s.WhiteIncre, s.BlackIncre = Millis(v.stateClocks.WhiteIncre), Millis(v.stateClocks.BlackIncre)
+	return nil
+}
+
+func (s GameState) MarshalJSON() ([]byte, error) {
+	type gameState GameState
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		gameState
+		stateClocks
+	}{s.EventType(), gameState(s), stateClocks{
+		s.WhiteTime.Milliseconds(), s.BlackTime.Milliseconds(),
+		s.WhiteIncre.Milliseconds(), s.BlackIncre.Milliseconds(),
+	}})
+}
+
+// OpponentGone tells that the opponent left the game, the win can be
+// claimed once ClaimWinInSeconds have elapsed while Gone is still true.
+type OpponentGone struct {
+	Gone bool `json:"gone"`
+	ClaimWinInSeconds int `json:"claimWinInSeconds,omitempty"`
+}
+
+// TakebackOffer is sent by WatchForBoardUpdates right after the game state
+// in which a player proposed a takeback. OfferedBy is "white" or "black", it
+// may be the authenticated user.
+type TakebackOffer struct {
+	GameID string
+	OfferedBy string
+}
+
+func (GameFull) EventType() string { return "gameFull" }
+func (GameState) EventType() string { return "gameState" }
+func (ChatLine) EventType() string { return "chatLine" }
+func (OpponentGone) EventType() string { return "opponentGone" }
+func (TakebackOffer) EventType() string { return "takebackOffer" }
+
+// decodeBoardEvent decodes a line of a board stream into the type of the
+// event, it returns nil for the types it does not know.
+func decodeBoardEvent(line []byte) (BoardEvent, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(line, &head); err != nil {
+		return nil, err
+	}
+	var event BoardEvent
+	var err error
+	switch head.Type {
+	case "gameFull":
+		v := GameFull{}
+		err = json.Unmarshal(line, &v)
+		event = v
+	case "gameState":
+		v := GameState{}
+		err = json.Unmarshal(line, &v)
+		event = v
+	case "chatLine":
+		v := ChatLine{}
+		err = json.Unmarshal(line, &v)
+		event = v
+	case "opponentGone":
+		v := OpponentGone{}
+		err = json.Unmarshal(line, &v)
+		event = v
+	}
+	return event, err
+}
+
+// Player is one side of a game. Board events describe it inline while game
+// exports nest the account under "user", both shapes decode into Player.
+type Player struct {
+	ID string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Title string `json:"title,omitempty"`
+	Rating int `json:"rating,omitempty"`
+	RatingDiff int `json:"ratingDiff,omitempty"`
+	Provisional bool `json:"provisional,omitempty"`
+	// AILevel is the Stockfish level when the side is played by the computer
+	AILevel int `json:"aiLevel,omitempty"`
+}
+
+// playerJSON is a Player in either of its shapes.
+type playerJSON struct {
+	playerFields
+	User *LightUser `json:"user"`
+	// streams of games by ID only send the user ID
+	UserID string `json:"userId"`
+}
+
+type playerFields Player
+
+func (*Player) jsonAlias() interface{} { return &playerJSON{} }
+
+func (p *Player) UnmarshalJSON(data []byte) error {
+	var v playerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Player(v.playerFields)
+	if v.User != nil {
+		p.ID = v.User.ID
+		p.Name = v.User.Name
+		p.Title = v.User.Title
+	}
+	if p.ID == "" {
+		p.ID = v.UserID
+	}
+	return nil
+}
+
+func (p Player) IsAI() bool {
+	return p.AILevel > 0
+}
+
+// DisplayName returns the name shown by Lichess, with the title if any.
+func (p Player) DisplayName() string {
+	switch {
+	case p.IsAI():
+		return fmt.Sprintf("Stockfish level %d", p.AILevel)
+	case p.Name == "":
+		return "Anonymous"
+	case p.Title != "":
+		return p.Title + " " + p.Name
+	}
+	return p.Name
+}
+
+type Players struct {
+	White Player `json:"white"`
+	Black Player `json:"black"`
 }
 
 /*
@@ -243,7 +700,9 @@ type BlackSide struct {
 // POST
 const challengeRespPath = "/api/challenge/%s/%s" // ChallengeID, Resp
 
-func (l Lichess) AuthenticateClient(id string, secret string, scopes []string) {
+// AuthenticateClient logs the user in, through the browser unless a token was
+// stored by an earlier run, and uses the resulting client for all requests.
+func (l *Lichess) AuthenticateClient(ctx context.Context, id string, secret string, scopes []string, options ...AuthenticateUserOption) error {
 	conf := &oauth2.Config{
 		ClientID:     id,
 		ClientSecret: secret,
@@ -254,133 +713,460 @@ func (l Lichess) AuthenticateClient(id string, secret string, scopes []string) {
 		},
 	}
 
-	resp, err := AuthenticateUser(conf)
+	client, err := AuthenticateUser(ctx, conf, append([]AuthenticateUserOption{WithAuthLogger(l.logger)}, options...)...)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	l.client = resp
+	l.SetClient(client)
+	return nil
 }
 
-func (l Lichess) GetClient() *AuthorizedClient {
-	return l.client
+// Endpoints of the PKCE flow, which has replaced the oauth.lichess.org ones
+const (
+	pkceAuthURL = lichessURL + "/oauth"
+	pkceTokenURL = lichessURL + "/api/token"
+)
+
+// AuthenticateClientPKCE logs the user in with the PKCE flow, which needs no
+// app registration nor secret: clientID is any name identifying the app.
+func (l *Lichess) AuthenticateClientPKCE(ctx context.Context, clientID string, scopes []string, options ...AuthenticateUserOption) error {
+	conf := &oauth2.Config{
+		ClientID: clientID,
+		Scopes: scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL: pkceAuthURL,
+			TokenURL: pkceTokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+
+	client, err := AuthenticateUser(ctx, conf, append([]AuthenticateUserOption{WithPKCE(), WithAuthLogger(l.logger)}, options...)...)
+	if err != nil {
+		return err
+	}
+	l.SetClient(client)
+	return nil
 }
 
-func (l Lichess) GetAccount() Profile {
-	fmt.Println(l.profile)	
-	fmt.Println("Check 1")
-	if (Profile{}) == l.profile {
-		fmt.Println("Check 2")
-		fmt.Println(l.GetClient())
-		fmt.Println("Check 3")
-		resp, err := l.client.Get(lichessURL + accountPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer resp.Body.Close()
+func (l *Lichess) GetClient() *AuthorizedClient {
+	return l.authorized()
+}
+
+// authorized returns the client of the authenticated user, nil before
+// authentication.
+func (l *Lichess) authorized() *AuthorizedClient {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.client
+}
 
-		fmt.Println("Check 4")
+// SetClient makes requests go through client, e.g. one returned by
+// NewClientWithToken. The cached account of the previous user is dropped.
+func (l *Lichess) SetClient(client *AuthorizedClient) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.client = client
+	l.account = nil
+}
 
-		dec := json.NewDecoder(resp.Body)
-		profile := Profile{}
-		err = dec.Decode(&profile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		
-		l.profile = profile
+// GetAccount returns the profile of the authenticated user, which is then
+// kept as the cached Account.
+func (l *Lichess) GetAccount(ctx context.Context) (Profile, error) {
+	profile := Profile{}
+	if err := l.getJSON(ctx, accountPath, &profile); err != nil {
+		return Profile{}, err
 	}
-	
-	return l.profile
+	l.mu.Lock()
+	l.account = &profile
+	l.mu.Unlock()
+	return profile, nil
 }
 
-func (l Lichess) GetBoardChannel() chan Board {
-	return l.currGame.Board
+// Account returns the profile cached by the last GetAccount call, ok is
+// false if there was none since the client was authenticated.
+func (l *Lichess) Account() (profile Profile, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.account == nil {
+		return Profile{}, false
+	}
+	return *l.account, true
 }
 
-func (l Lichess) FindAndStartGame(rated bool, time uint8, incre uint8,
-								  variant string, color string, ratingRange string)  {
-	var wg sync.WaitGroup
-	wg.Add(1)
+// GetEmail returns the email address of the authenticated user.
+func (l *Lichess) GetEmail(ctx context.Context) (string, error) {
+	if err := l.authorized().requireScope(ScopeEmailRead); err != nil {
+		return "", err
+	}
+	resp := struct {
+		Email string `json:"email"`
+	}{}
+	err := l.getJSON(ctx, emailPath, &resp)
+	return resp.Email, err
+}
 
-	event := Event{}
-	WatchForGame(l.client, &event, &wg)
-	SeekGame(l.client, rated, time, incre, variant, color, ratingRange)
+// GetPreferences returns the settings of the authenticated user, along with
+// the language of the interface.
+func (l *Lichess) GetPreferences(ctx context.Context) (Preferences, error) {
+	if err := l.authorized().requireScope(ScopePreferenceRead); err != nil {
+		return Preferences{}, err
+	}
+	resp := struct {
+		Prefs Preferences `json:"prefs"`
+		Language string `json:"language"`
+	}{}
+	err := l.getJSON(ctx, prefPath, &resp)
+	resp.Prefs.Language = resp.Language
+	return resp.Prefs, err
+}
 
-	wg.Wait()
-	l.currGame = event.Game
-	l.currGame.Board = make(chan Board)
+// SetPreference changes a setting of the authenticated user, key is the
+// JSON name of a Preferences field, such as "pieceSet" or "premove", and
+// value is given as Lichess expects it, e.g. "1" for true or a mode number.
+func (l *Lichess) SetPreference(ctx context.Context, key string, value string) error {
+	if err := l.authorized().requireScope(ScopePreferenceWrite); err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set(key, value)
+	return l.postForm(ctx, fmt.Sprintf(setPrefPath, url.PathEscape(key)), values)
 }
 
-func WatchForGame(client *AuthorizedClient, event *Event, wg *sync.WaitGroup) {
-	defer wg.Done()
+// GetKidMode reports whether the authenticated user is in kid mode.
+func (l *Lichess) GetKidMode(ctx context.Context) (bool, error) {
+	if err := l.authorized().requireScope(ScopePreferenceRead); err != nil {
+		return false, err
+	}
+	resp := struct {
+		Kid bool `json:"kid"`
+	}{}
+	err := l.getJSON(ctx, kidModePath, &resp)
+	return resp.Kid, err
+}
 
-	resp, err := client.Get(lichessURL + streamEventPath)
-	if err != nil {
-		log.Fatal(err)
+// SetKidMode enables or disables kid mode for the authenticated user.
+func (l *Lichess) SetKidMode(ctx context.Context, enabled bool) error {
+	if err := l.authorized().requireScope(ScopePreferenceWrite); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
+	path := withQuery(kidModePath, url.Values{"v": {strconv.FormatBool(enabled)}})
+	return l.postForm(ctx, path, nil)
+}
 
-	dec := json.NewDecoder(resp.Body)
-	eventResp := Event{}
-	for {
-		err := dec.Decode(&event)
-		if err != nil {
-			log.Fatal(err)
-		}
+// GetBoardChannel returns the board events of the game started last by
+// FindAndStartGame or PlayAgainstAI, nil once it is over.
+func (l *Lichess) GetBoardChannel() chan BoardEvent {
+	game, _ := l.CurrentGame()
+	return game.Board
+}
 
-		switch event.Type {
-			case "gameStart":
-				*event = eventResp;
-				return
-			case "challenge":
-				fmt.Printf("Challenge from %s\n", event.Challenge.Challenger.Name)
-				reader := bufio.NewReader(os.Stdin)
-				fmt.Print("Do you accept? (y or n): ")
-				response, _ := reader.ReadString('\n')
-
-				if response == "y" {
-					client.Post(lichessURL + challengeRespPath + event.Challenge.ID + "/accept", "plain/text", strings.NewReader(""))
-				} else if response == "n" {
-					client.Post(lichessURL + challengeRespPath + event.Challenge.ID + "/decline", "plain/text", strings.NewReader(""))
-				} else {
-					fmt.Println("Invalid response")
-				}
-		}
+// CurrentGame returns the game started last by FindAndStartGame or
+// PlayAgainstAI, ok is false once its session ended.
+func (l *Lichess) CurrentGame() (game Game, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	game, ok = l.games[l.current]
+	return game, ok
+}
+
+// ActiveGames returns the games followed by a GameSession of the client.
+func (l *Lichess) ActiveGames() []Game {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	games := make([]Game, 0, len(l.games))
+	for _, game := range l.games {
+		games = append(games, game)
 	}
+	return games
 }
 
-func SeekGame(client *AuthorizedClient, rated bool, time uint8, incre uint8,
-					variant string, color string, ratingRange string) {
-	
-	params := fmt.Sprintf("rated=%t&time=%d&increment=%d&variant=%s&color=%s&ratingRange=%s",
-							rated, time, incre, variant, color, ratingRange)
-	_, err := client.Post(lichessURL + seekPath, "application/x-www-form-urlencoded", 
-							strings.NewReader(params))
-	if err != nil {
-		log.Fatal(err)
+// registerGame records a game followed by a session, as the current one if
+// current is set.
+func (l *Lichess) registerGame(game Game, current bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.games == nil {
+		l.games = map[string]Game{}
+	}
+	l.games[game.ID] = game
+	if current {
+		l.current = game.ID
+	}
+}
+
+func (l *Lichess) forgetGame(gameID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.games, gameID)
+}
+
+// StreamEvents sends every event of the authenticated user's event stream
+// to events until ctx is cancelled or Lichess reports an error, events need
+// not be read for it to return. Dropped connections are reestablished,
+// Lichess then announcing the pending challenges and ongoing games again.
+func (l *Lichess) StreamEvents(ctx context.Context, events chan<- Event) error {
+	return runStream(ctx, l, l.eventSpec(ctx), events)
+}
+
+// EventStream follows the event stream of the authenticated user like
+// StreamEvents.
+func (l *Lichess) EventStream(ctx context.Context) *Stream[Event] {
+	return newStream(ctx, l, l.eventSpec(ctx))
+}
+
+func (l *Lichess) eventSpec(ctx context.Context) streamSpec[Event] {
+	return streamSpec[Event]{
+		path: streamEventPath,
+		reconnect: true,
+		handle: func(event *Event) bool {
+			if event.Game.ID != "" {
+				event.Game.lichess = l
+			}
+			if event.Type == "gameStart" && l.opponentLookup {
+				l.lookupOpponent(ctx, &event.Game)
+			}
+			if event.Type == "gameFinish" && l.onGameEnd != nil {
+				go l.summarizeGame(ctx, event.Game.ID)
+			}
+			return true
+		},
 	}
 }
 
-func (l Lichess) WatchForBoardUpdates(gameId string, ch chan<- Board, wg *sync.WaitGroup) {
-	defer wg.Done()
+// SetOpponentLookup makes StreamEvents fetch the opponent's public profile
+// before delivering a gameStart event, so bots can adapt to the opponent's
+// strength right away.
+func (l *Lichess) SetOpponentLookup(enabled bool) {
+	l.opponentLookup = enabled
+}
 
-	resp, err := l.client.Get(lichessURL + streamBoardPath + gameId)
+// lookupOpponent fills in the opponent profile of game. Failures are not
+// fatal, the game is delivered without a profile, and AI opponents have none.
+func (l *Lichess) lookupOpponent(ctx context.Context, game *Game) {
+	if game.Opponent.ID == "" || game.Opponent.AI != 0 {
+		return
+	}
+	profile, err := l.GetUser(ctx, game.Opponent.ID)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
-	defer resp.Body.Close()
+	game.OpponentProfile = &profile
+}
+
+// WatchForGame waits for a game of the user to start. Challenges received
+// meanwhile are answered by onChallenge, which may be nil to leave them
+// pending.
+func WatchForGame(parent context.Context, client *AuthorizedClient, onChallenge func(Challenge) Decision) (Event, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
 
-	dec := json.NewDecoder(resp.Body)
-	for {
-		boardResp := Board{}
-		err := dec.Decode(&boardResp)
-		if err != nil {
-			if err == io.EOF {
-				return
+	started := make(chan Game, 1)
+	errs := (&Lichess{client: client}).RegisterHandler(ctx, EventHandler{
+		OnChallenge: onChallenge,
+		OnGameStart: func(game Game) {
+			select {
+			case started <- game:
+			default:
 			}
-			log.Fatal(err)
+			cancel()
+		},
+	})
+	err := <-errs
+	select {
+	case game := <-started:
+		return Event{Type: "gameStart", Game: game}, nil
+	default:
+	}
+	switch {
+	case err != nil:
+		return Event{}, err
+	case parent.Err() != nil:
+		return Event{}, parent.Err()
+	}
+	return Event{}, io.ErrUnexpectedEOF
+}
+
+// SeekGame creates a real-time seek, see Lichess.Seek.
+func SeekGame(ctx context.Context, client *AuthorizedClient, rated bool, time uint8, incre uint8,
+					variant VariantKey, color Color, ratingRange RatingRange) (*Seek, error) {
+	return (&Lichess{client: client}).Seek(ctx, SeekOptions{
+		Rated: rated,
+		Time: int(time),
+		Increment: int(incre),
+		Variant: variant,
+		Color: color,
+		RatingRange: ratingRange,
+	})
+}
+
+// SendChat posts text to the player or spectator room of a game.
+func (l *Lichess) SendChat(ctx context.Context, gameID string, room string, text string) error {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("room", room)
+	values.Set("text", text)
+	return l.postForm(ctx, l.playPath(fmt.Sprintf(sendChatPath, gameID)), values)
+}
+
+// ChatLine is a message of a game chat. Room is only known for the lines of
+// the board stream.
+type ChatLine struct {
+	User string `json:"user"`
+	Text string `json:"text"`
+	Room string `json:"room,omitempty"`
+}
+
+// UnmarshalJSON also accepts the "username" board streams send in place of
+// "user".
+// chatLineJSON is a ChatLine of either the chat or the board stream.
+type chatLineJSON struct {
+	chatLineFields
+	Username string `json:"username"`
+}
+
+type chatLineFields ChatLine
+
+func (*ChatLine) jsonAlias() interface{} { return &chatLineJSON{} }
+
+func (c *ChatLine) UnmarshalJSON(data []byte) error {
+	var v chatLineJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = ChatLine(v.chatLineFields)
+	if c.User == "" {
+		c.User = v.Username
+	}
+	return nil
+}
+
+// GetChat returns the messages of the player room of a game, oldest first.
+func (l *Lichess) GetChat(ctx context.Context, gameID string) ([]ChatLine, error) {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return nil, err
+	}
+	lines := []ChatLine{}
+	err := l.getJSON(ctx, l.playPath(fmt.Sprintf(chatPath, url.PathEscape(gameID))), &lines)
+	return lines, err
+}
+
+// GameChat is the chat of a game split by room, oldest lines first.
+type GameChat struct {
+	Player []ChatLine
+	Spectator []ChatLine
+}
+
+// Add appends a line to its room, such as a line of GameSession.Chat
+// received after the history was fetched.
+func (c *GameChat) Add(line ChatLine) {
+	if line.Room == "spectator" {
+		c.Spectator = append(c.Spectator, line)
+		return
+	}
+	c.Player = append(c.Player, line)
+}
+
+// GetGameChat returns the chat history of a game, to backfill a chat pane
+// when reopening the game. Lichess only serves the history of the player
+// room, the spectator lines being those received on the board stream since,
+// so Spectator starts empty.
+func (l *Lichess) GetGameChat(ctx context.Context, gameID string) (GameChat, error) {
+	lines, err := l.GetChat(ctx, gameID)
+	if err != nil {
+		return GameChat{}, err
+	}
+	chat := GameChat{Player: []ChatLine{}, Spectator: []ChatLine{}}
+	for _, line := range lines {
+		if line.Room == "" {
+			line.Room = "player"
 		}
+		chat.Add(line)
+	}
+	return chat, nil
+}
 
-		ch <- boardResp
+// WatchForBoardUpdates sends the events of a game's board stream to ch until
+// the game is over, ctx is done or the stream fails for good. Dropped
+// connections are reestablished transparently, the gameFull event Lichess
+// repeats on reconnection is only forwarded if its moves, status or winner
+// differ from the last ones delivered. Each event's NewMoves lists the moves
+// that were not seen before. The stream is closed when ctx is done even
+// while ch is not being read, so cancelling ctx is enough to release it;
+// BoardStream does the same with a Close method.
+func (l *Lichess) WatchForBoardUpdates(ctx context.Context, gameId string, ch chan<- BoardEvent) error {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return err
 	}
-}
\ No newline at end of file
+	return runStream(ctx, l, l.boardSpec(gameId), ch)
+}
+
+// BoardStream follows the board stream of a game of the authenticated user
+// like WatchForBoardUpdates.
+func (l *Lichess) BoardStream(ctx context.Context, gameId string) *Stream[BoardEvent] {
+	if err := l.authorized().requireScope(l.playScope()); err != nil {
+		return failedStream[BoardEvent](err)
+	}
+	return newStream(ctx, l, l.boardSpec(gameId))
+}
+
+// boardSpec follows the board stream of a game, or the bot game stream when
+// playing as a bot.
+func (l *Lichess) boardSpec(gameId string) streamSpec[BoardEvent] {
+	cursor := NewMoveCursor()
+	status := ""
+	// last is the state of the previous game event, nil before the first
+	var last *GameState
+	// takebacks proposed as of the previous state, by color
+	proposed := map[string]bool{}
+	return streamSpec[BoardEvent]{
+		path: l.playPath(fmt.Sprintf(streamBoardPath, gameId)),
+		reconnect: true,
+		finished: func() bool {
+			return !isOngoing(status)
+		},
+		decode: decodeBoardEvent,
+		handle: func(e *BoardEvent) bool {
+			switch event := (*e).(type) {
+			case GameFull:
+				// the gameFull repeated on reconnection is dropped unless the
+				// game went on, or ended, while the stream was down
+				changed := last == nil || stateChanged(*last, event.State)
+				event.State.NewMoves = cursor.Advance(gameId, event)
+				status = string(event.State.Status)
+				last = &event.State
+				*e = event
+				return changed
+			case GameState:
+				event.NewMoves = cursor.Advance(gameId, event)
+				status = string(event.Status)
+				last = &event
+				*e = event
+			case nil:
+				return false
+			}
+			return true
+		},
+		follow: func(e BoardEvent) (BoardEvent, bool) {
+			var white, black bool
+			switch event := e.(type) {
+			case GameFull:
+				white, black = event.State.WhiteTakeback, event.State.BlackTakeback
+			case GameState:
+				white, black = event.WhiteTakeback, event.BlackTakeback
+			default:
+				return nil, false
+			}
+			var offer BoardEvent
+			if white && !proposed["white"] {
+				offer = TakebackOffer{GameID: gameId, OfferedBy: "white"}
+			}
+			if black && !proposed["black"] {
+				offer = TakebackOffer{GameID: gameId, OfferedBy: "black"}
+			}
+			proposed["white"], proposed["black"] = white, black
+			return offer, offer != nil
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package lichesstest
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// feed is the queue of lines of a stream. Each line is sent once, to
+// whichever connection to the stream reads it first, so a client that
+// reconnects goes on where it stopped.
+type feed struct {
+	mu sync.Mutex
+	lines [][]byte
+	ended bool
+	// changed is closed and replaced whenever lines are pushed or the feed
+	// ends
+	changed chan struct{}
+}
+
+func newFeed() *feed {
+	return &feed{changed: make(chan struct{})}
+}
+
+func (f *feed) push(lines ...[]byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, line := range lines {
+		line = append([]byte(nil), bytes.TrimRight(line, "\r\n")...)
+		f.lines = append(f.lines, append(line, '\n'))
+	}
+	f.notify()
+}
+
+func (f *feed) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ended = true
+	f.notify()
+}
+
+// notify must be called with mu held.
+func (f *feed) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// next takes the queued lines, reporting whether the feed ended once they
+// are sent, along with a channel closed on the next change.
+func (f *feed) next() ([][]byte, bool, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines := f.lines
+	f.lines = nil
+	return lines, f.ended, f.changed
+}
+
+// serve sends the lines of the feed as an NDJSON stream, until the feed
+// ends or the client goes away.
+func (f *feed) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for {
+		lines, ended, changed := f.next()
+		for _, line := range lines {
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if ended {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// PlayFile queues the lines of an NDJSON fixture file on the stream at path,
+// such as a recording of a real stream. Its blank lines are sent as
+// keep-alives.
+func (s *Server) PlayFile(path string, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	s.Push(path, lines...)
+	return nil
+}
@@ -0,0 +1,450 @@
+// Package lichesstest runs a fake Lichess server, so applications built on
+// github.com/hmccarty/lichess can be tested deterministically and without a
+// Lichess account.
+//
+// The server answers the account, board and challenge endpoints from its
+// state, and plays back on the streams the events queued by the test, or
+// recorded in NDJSON fixture files:
+//
+//	srv := lichesstest.NewServer()
+//	defer srv.Close()
+//	srv.PlayFile(lichesstest.EventStreamPath, "testdata/events.ndjson")
+//	l := srv.Client()
+package lichesstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hmccarty/lichess"
+)
+
+// Paths of the streams, to queue events on
+const (
+	EventStreamPath = "/api/stream/event"
+	boardStreamPath = "/api/board/game/stream/%s" // GameID
+	botStreamPath = "/api/bot/game/stream/%s" // GameID
+)
+
+// BoardStream returns the path of the board stream of a game, to play a
+// fixture on. Bots follow the bot stream, which gets the events sent with
+// SendBoardEvent too.
+func BoardStream(gameID string) string {
+	return fmt.Sprintf(boardStreamPath, gameID)
+}
+
+// Request is a request received by the server, other than a stream.
+type Request struct {
+	Method string
+	Path string
+	Form url.Values
+}
+
+// Server is a fake Lichess API, its URL being that of an httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	account lichess.Profile
+	// challenges are the pending challenges by ID, in the order received
+	challenges map[string]lichess.Challenge
+	challengeIDs []string
+	// games holds the state of the games started with StartGame
+	games map[string]*lichess.GameState
+	moves map[string][]string
+	requests []Request
+	feeds map[string]*feed
+	lastID int
+}
+
+// NewServer starts a server with a default account, which is closed by
+// Close.
+func NewServer() *Server {
+	s := &Server{
+		account: lichess.Profile{ID: "lichesstest", Username: "LichessTest"},
+		challenges: map[string]lichess.Challenge{},
+		games: map[string]*lichess.GameState{},
+		moves: map[string][]string{},
+		feeds: map[string]*feed{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client authenticated on the server with a token granting
+// every scope.
+func (s *Server) Client(options ...lichess.ClientOption) *lichess.Lichess {
+	l := lichess.NewLichess(append([]lichess.ClientOption{lichess.WithBaseURL(s.URL)}, options...)...)
+	l.SetClient(lichess.NewClientWithToken("lichesstest"))
+	return l
+}
+
+// SetAccount changes the profile returned for the authenticated user.
+func (s *Server) SetAccount(profile lichess.Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.account = profile
+}
+
+// Requests returns the requests received so far, streams excluded.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Moves returns the moves played by the client in a game, in UCI notation.
+func (s *Server) Moves(gameID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.moves[gameID]...)
+}
+
+// AddChallenge makes challenge pending and announces it on the event
+// stream. An empty ID is generated.
+func (s *Server) AddChallenge(challenge lichess.Challenge) lichess.Challenge {
+	s.mu.Lock()
+	if challenge.ID == "" {
+		challenge.ID = s.newID()
+	}
+	if challenge.Status == "" {
+		challenge.Status = "created"
+	}
+	s.addChallenge(challenge)
+	s.mu.Unlock()
+	s.SendEvent(lichess.Event{Type: "challenge", Challenge: challenge})
+	return challenge
+}
+
+// addChallenge must be called with mu held.
+func (s *Server) addChallenge(challenge lichess.Challenge) {
+	if _, ok := s.challenges[challenge.ID]; !ok {
+		s.challengeIDs = append(s.challengeIDs, challenge.ID)
+	}
+	s.challenges[challenge.ID] = challenge
+}
+
+// Challenge returns a challenge received or sent by the client.
+func (s *Server) Challenge(id string) (lichess.Challenge, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, ok := s.challenges[id]
+	return challenge, ok
+}
+
+// StartGame announces game on the event stream and opens its board stream
+// with full, the moves then played by the client are answered with new game
+// states.
+func (s *Server) StartGame(game lichess.Game, full lichess.GameFull) error {
+	if game.ID == "" {
+		game.ID = full.ID
+	}
+	s.mu.Lock()
+	state := full.State
+	if state.Status == "" {
+		state.Status = lichess.StatusStarted
+	}
+	s.games[game.ID] = &state
+	s.mu.Unlock()
+
+	if err := s.SendEvent(lichess.Event{Type: "gameStart", Game: game}); err != nil {
+		return err
+	}
+	return s.SendBoardEvent(game.ID, full)
+}
+
+// SendEvent queues event on the event stream.
+func (s *Server) SendEvent(event lichess.Event) error {
+	return s.Send(EventStreamPath, event)
+}
+
+// SendBoardEvent queues event on the board and bot streams of a game.
+func (s *Server) SendBoardEvent(gameID string, event lichess.BoardEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = withType(line, event.EventType())
+	s.Push(fmt.Sprintf(boardStreamPath, gameID), line)
+	s.Push(fmt.Sprintf(botStreamPath, gameID), line)
+	return nil
+}
+
+// withType adds the type field Lichess sends with each board event, for the
+// events whose JSON does not have it.
+func withType(line []byte, eventType string) []byte {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(line, &head) != nil || head.Type != "" || len(line) < 2 {
+		return line
+	}
+	typed := fmt.Sprintf(`{"type":%q`, eventType)
+	if string(line) != "{}" {
+		typed += ","
+	}
+	return append([]byte(typed), line[1:]...)
+}
+
+// Send queues v, encoded in JSON, on the stream at path.
+func (s *Server) Send(path string, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.Push(path, line)
+	return nil
+}
+
+// Push queues raw lines on the stream at path, an empty one being a
+// keep-alive.
+func (s *Server) Push(path string, lines ...[]byte) {
+	s.feed(path).push(lines...)
+}
+
+// EndStream ends the stream at path once its queued lines were sent. Clients
+// reopen the streams that reconnect, such as the event stream, and then get
+// the lines queued since.
+func (s *Server) EndStream(path string) {
+	s.feed(path).end()
+}
+
+func (s *Server) feed(path string) *feed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.feeds[path]
+	if !ok {
+		f = newFeed()
+		s.feeds[path] = f
+	}
+	return f
+}
+
+// newID must be called with mu held.
+func (s *Server) newID() string {
+	s.lastID++
+	return fmt.Sprintf("test%04d", s.lastID)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if isStream(r.URL.Path) {
+		s.feed(r.URL.Path).serve(w, r)
+		return
+	}
+	r.ParseForm()
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Form: r.PostForm})
+	s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/account":
+		s.mu.Lock()
+		account := s.account
+		s.mu.Unlock()
+		writeJSON(w, account)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/challenge":
+		s.listChallenges(w)
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[0] == "api" && parts[1] == "challenge":
+		s.respondChallenge(w, parts[2], parts[3])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "api" && parts[1] == "challenge":
+		s.createChallenge(w, r, parts[2])
+	case r.Method == http.MethodPost && len(parts) >= 5 && parts[0] == "api" && (parts[1] == "board" || parts[1] == "bot") && parts[2] == "game":
+		s.playGame(w, parts[3], parts[4:])
+	default:
+		notFound(w)
+	}
+}
+
+func isStream(path string) bool {
+	return path == EventStreamPath ||
+		strings.HasPrefix(path, "/api/board/game/stream/") ||
+		strings.HasPrefix(path, "/api/bot/game/stream/") ||
+		strings.HasPrefix(path, "/api/stream/")
+}
+
+func (s *Server) listChallenges(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := lichess.Challenges{In: []lichess.Challenge{}, Out: []lichess.Challenge{}}
+	for _, id := range s.challengeIDs {
+		challenge := s.challenges[id]
+		if challenge.Status != "created" {
+			continue
+		}
+		if challenge.Challenger.ID == s.account.ID {
+			resp.Out = append(resp.Out, challenge)
+		} else {
+			resp.In = append(resp.In, challenge)
+		}
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) respondChallenge(w http.ResponseWriter, id string, decision string) {
+	s.mu.Lock()
+	challenge, ok := s.challenges[id]
+	if ok {
+		switch decision {
+		case "accept":
+			challenge.Status = "accepted"
+		case "decline":
+			challenge.Status = "declined"
+		case "cancel":
+			challenge.Status = "canceled"
+		default:
+			ok = false
+		}
+		s.challenges[id] = challenge
+	}
+	s.mu.Unlock()
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeOK(w)
+}
+
+func (s *Server) createChallenge(w http.ResponseWriter, r *http.Request, username string) {
+	s.mu.Lock()
+	challenge := lichess.Challenge{
+		ID: s.newID(),
+		Status: "created",
+		Challenger: lichess.Challenger{ID: s.account.ID, Name: s.account.Username},
+		DestUser: &lichess.Challenger{ID: strings.ToLower(username), Name: username},
+		Rated: r.PostForm.Get("rated") == "true",
+		Color: lichess.Color(r.PostForm.Get("color")),
+		InitialFen: r.PostForm.Get("fen"),
+		Direction: "out",
+	}
+	challenge.URL = s.URL + "/" + challenge.ID
+	if variant := r.PostForm.Get("variant"); variant != "" {
+		challenge.Variant.Key = lichess.VariantKey(variant)
+	} else {
+		challenge.Variant.Key = lichess.VariantStandard
+	}
+	if challenge.Color == "" {
+		challenge.Color = lichess.ColorRandom
+	}
+	challenge.TimeControl, challenge.Speed = timeControl(r.PostForm)
+	s.addChallenge(challenge)
+	s.mu.Unlock()
+	writeJSON(w, challenge)
+}
+
+// timeControl reads the time control of a challenge request, its speed
+// estimated the way Lichess does from the clock for 40 moves.
+func timeControl(form url.Values) (lichess.ChallengeTimeControl, lichess.Speed) {
+	limit, _ := strconv.Atoi(form.Get("clock.limit"))
+	increment, _ := strconv.Atoi(form.Get("clock.increment"))
+	days, _ := strconv.Atoi(form.Get("days"))
+	switch {
+	case form.Has("clock.limit"):
+		tc := lichess.ChallengeTimeControl{
+			Type: "clock",
+			Limit: limit,
+			Increment: increment,
+			Show: fmt.Sprintf("%s+%d", showMinutes(limit), increment),
+		}
+		return tc, estimatedSpeed(limit + 40*increment)
+	case days > 0:
+		return lichess.ChallengeTimeControl{Type: "correspondence", DaysPerTurn: days}, lichess.SpeedCorrespondence
+	}
+	return lichess.ChallengeTimeControl{Type: "unlimited"}, lichess.SpeedCorrespondence
+}
+
+func showMinutes(seconds int) string {
+	switch seconds {
+	case 15:
+		return "¼"
+	case 30:
+		return "½"
+	case 45:
+		return "¾"
+	case 90:
+		return "1.5"
+	}
+	return strconv.Itoa(seconds / 60)
+}
+
+func estimatedSpeed(seconds int) lichess.Speed {
+	switch {
+	case seconds < 30:
+		return lichess.SpeedUltraBullet
+	case seconds < 180:
+		return lichess.SpeedBullet
+	case seconds < 480:
+		return lichess.SpeedBlitz
+	case seconds < 1500:
+		return lichess.SpeedRapid
+	}
+	return lichess.SpeedClassical
+}
+
+// playGame answers the board and bot actions on a game, moves being
+// recorded and, for games started with StartGame, echoed in a new state.
+func (s *Server) playGame(w http.ResponseWriter, gameID string, action []string) {
+	s.mu.Lock()
+	state := s.games[gameID]
+	var next *lichess.GameState
+	switch action[0] {
+	case "move":
+		if len(action) != 2 {
+			s.mu.Unlock()
+			notFound(w)
+			return
+		}
+		s.moves[gameID] = append(s.moves[gameID], action[1])
+		if state != nil {
+			if state.Moves != "" {
+				state.Moves += " "
+			}
+			state.Moves += action[1]
+			next = state
+		}
+	case "resign", "abort":
+		if state != nil {
+			state.Status = lichess.StatusResign
+			if action[0] == "abort" {
+				state.Status = lichess.StatusAborted
+			}
+			next = state
+		}
+	}
+	var sent lichess.GameState
+	if next != nil {
+		sent = *next
+		sent.NewMoves = nil
+	}
+	s.mu.Unlock()
+
+	if next != nil {
+		s.SendBoardEvent(gameID, sent)
+		if !sent.Status.IsOngoing() {
+			s.EndStream(fmt.Sprintf(boardStreamPath, gameID))
+			s.EndStream(fmt.Sprintf(botStreamPath, gameID))
+		}
+	}
+	writeOK(w)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeOK(w http.ResponseWriter) {
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+func notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"error":"Not found"}`))
+}
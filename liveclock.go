@@ -0,0 +1,186 @@
+package lichess
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * LIVE CLOCK
+ */
+
+// ClockTracker keeps the clocks of a game from its gameFull and gameState
+// events, and runs the clock of the side to move in between, so GUIs can
+// render ticking clocks and bots can budget their time. It is safe for
+// concurrent use.
+type ClockTracker struct {
+	mu sync.Mutex
+	white time.Duration
+	black time.Duration
+	whiteIncrement time.Duration
+	blackIncrement time.Duration
+	turn Color
+	// running is set when the clock of turn runs, Lichess starting the
+	// clocks after the first move of each player
+	running bool
+	// at is when the clocks were last received
+	at time.Time
+	// blackStarts is set for games from a position with black to move
+	blackStarts bool
+
+	lowTime time.Duration
+	low chan Color
+	// warned holds the colors already sent on low
+	warned map[Color]bool
+	timer *time.Timer
+}
+
+// NewClockTracker returns a tracker reporting on LowTime when a player has
+// less than lowTime left, zero disabling the notifications.
+func NewClockTracker(lowTime time.Duration) *ClockTracker {
+	return &ClockTracker{
+		turn: ColorWhite,
+		lowTime: lowTime,
+		low: make(chan Color, 2),
+		warned: map[Color]bool{},
+	}
+}
+
+// Follow updates the tracker with every event received on ch until it is
+// closed, the clocks are then stopped.
+func (c *ClockTracker) Follow(ch <-chan BoardEvent) {
+	for e := range ch {
+		c.Update(e)
+	}
+	c.Stop()
+}
+
+// Update takes the clocks of a GameFull or GameState, other events are
+// ignored.
+func (c *ClockTracker) Update(e BoardEvent) {
+	var state GameState
+	switch event := e.(type) {
+	case GameFull:
+		c.mu.Lock()
+		c.blackStarts = strings.Contains(event.InitialFen, " b ")
+		c.mu.Unlock()
+		state = event.State
+	case GameState:
+		state = event
+	default:
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	plies := len(strings.Fields(state.Moves))
+	c.white, c.black = state.Clocks()
+	c.whiteIncrement, c.blackIncrement = state.WhiteIncre, state.BlackIncre
+	c.turn = ColorWhite
+	if (plies%2 == 1) != c.blackStarts {
+		c.turn = ColorBlack
+	}
+	c.running = plies >= 2 && (state.Status == "" || state.Status.IsOngoing())
+	c.at = time.Now()
+	c.schedule()
+}
+
+// Stop freezes the clocks, e.g. once the game is over.
+func (c *ClockTracker) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.white, c.black = c.remaining(ColorWhite), c.remaining(ColorBlack)
+	c.running = false
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// RemainingTime returns the time left to color now, the clock of the side
+// to move being run since the last event. It does not go below zero.
+func (c *ClockTracker) RemainingTime(color Color) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remaining(color)
+}
+
+// Increment returns the increment of color, which may differ from the
+// clock's after berserking.
+func (c *ClockTracker) Increment(color Color) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if color == ColorBlack {
+		return c.blackIncrement
+	}
+	return c.whiteIncrement
+}
+
+// Turn returns the side whose clock runs.
+func (c *ClockTracker) Turn() Color {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.turn
+}
+
+// LowTime receives the color of a player once their time falls below the
+// low time, again if it fell back below after going above it. Notifications
+// are dropped while the channel is full.
+func (c *ClockTracker) LowTime() <-chan Color {
+	return c.low
+}
+
+// remaining must be called with mu held.
+func (c *ClockTracker) remaining(color Color) time.Duration {
+	left := c.white
+	if color == ColorBlack {
+		left = c.black
+	}
+	if c.running && color == c.turn {
+		left -= time.Since(c.at)
+	}
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// schedule notifies the players already low on time and arms the timer for
+// the side to move. It must be called with mu held.
+func (c *ClockTracker) schedule() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if c.lowTime <= 0 {
+		return
+	}
+	for _, color := range []Color{ColorWhite, ColorBlack} {
+		left := c.remaining(color)
+		switch {
+		case left >= c.lowTime:
+			c.warned[color] = false
+		case !c.warned[color]:
+			c.warn(color)
+		}
+	}
+	if !c.running || c.warned[c.turn] {
+		return
+	}
+	turn := c.turn
+	c.timer = time.AfterFunc(c.remaining(turn)-c.lowTime, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.running && c.turn == turn && !c.warned[turn] && c.remaining(turn) < c.lowTime+time.Millisecond {
+			c.warn(turn)
+		}
+	})
+}
+
+// warn must be called with mu held.
+func (c *ClockTracker) warn(color Color) {
+	c.warned[color] = true
+	select {
+	case c.low <- color:
+	default:
+	}
+}
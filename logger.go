@@ -0,0 +1,47 @@
+package lichess
+
+/*
+ * LOGGING
+ */
+
+// Logger receives what the client has to say, nothing is logged unless one
+// is set. The methods take a message and alternating keys and values, like
+// log/slog, so a *slog.Logger can be used as is and a zap.SugaredLogger
+// through its Debugw, Infow, Warnw and Errorw methods.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any) {}
+func (nopLogger) Warn(string, ...any) {}
+func (nopLogger) Error(string, ...any) {}
+
+// WithLogger sends the logs of the client, and of its authentication, to
+// logger.
+func WithLogger(logger Logger) ClientOption {
+	return func(l *Lichess) {
+		l.logger = logger
+	}
+}
+
+func (l *Lichess) SetLogger(logger Logger) {
+	l.logger = logger
+}
+
+// log returns the configured logger, or one discarding everything.
+func (l *Lichess) log() Logger {
+	return loggerOrNop(l.logger)
+}
+
+func loggerOrNop(logger Logger) Logger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	return logger
+}
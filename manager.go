@@ -0,0 +1,144 @@
+package lichess
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+ * GAME MANAGER
+ */
+
+// GameManager follows every game of the authenticated user at once, each in
+// its own GameSession, for bots playing correspondence games or simuls. The
+// games already ongoing when the manager starts are picked up too. It is
+// safe for concurrent use.
+type GameManager struct {
+	lichess *Lichess
+
+	mu sync.RWMutex
+	games map[string]*GameSession
+	err error
+	// sessions counts the sessions still running, those of finished games
+	// included
+	sessions sync.WaitGroup
+
+	started chan *GameSession
+	done chan struct{}
+	cancel context.CancelFunc
+}
+
+// NewGameManager starts following the event stream until ctx is done or the
+// manager is closed.
+func (l *Lichess) NewGameManager(ctx context.Context) *GameManager {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &GameManager{
+		lichess: l,
+		games: map[string]*GameSession{},
+		started: make(chan *GameSession),
+		done: make(chan struct{}),
+		cancel: cancel,
+	}
+
+	events := make(chan Event)
+	go func() {
+		err := l.StreamEvents(ctx, events)
+		// the error is set before events is closed, and done with it, so
+		// Err is final once Done is
+		m.mu.Lock()
+		if err != nil && err != context.Canceled {
+			m.err = err
+		}
+		m.mu.Unlock()
+		close(events)
+	}()
+	go func() {
+		defer close(m.done)
+		defer close(m.started)
+		for event := range events {
+			session := m.apply(ctx, event)
+			if session == nil {
+				continue
+			}
+			select {
+			case m.started <- session:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return m
+}
+
+// apply records a game starting or finishing, and returns the session of a
+// game that just started.
+func (m *GameManager) apply(ctx context.Context, event Event) *GameSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch event.Type {
+	case "gameStart":
+		if _, ok := m.games[event.Game.ID]; ok {
+			return nil
+		}
+		session := m.lichess.NewGameSession(ctx, event.Game)
+		m.games[event.Game.ID] = session
+		m.sessions.Add(1)
+		go func() {
+			<-session.Done()
+			m.sessions.Done()
+		}()
+		return session
+	case "gameFinish":
+		if session, ok := m.games[event.Game.ID]; ok {
+			session.Finish(event.Game)
+			delete(m.games, event.Game.ID)
+		}
+	}
+	return nil
+}
+
+// Started delivers the session of every game as it starts. The manager waits
+// for each session to be received, so the channel must be drained, as must
+// be the Updates of each session.
+func (m *GameManager) Started() <-chan *GameSession {
+	return m.started
+}
+
+// Game returns the session of an ongoing game.
+func (m *GameManager) Game(gameID string) (*GameSession, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.games[gameID]
+	return session, ok
+}
+
+// Games returns the sessions of the ongoing games. A game is dropped once its
+// gameFinish event is received.
+func (m *GameManager) Games() []*GameSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]*GameSession, 0, len(m.games))
+	for _, session := range m.games {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Done is closed once the event stream has ended.
+func (m *GameManager) Done() <-chan struct{} {
+	return m.done
+}
+
+// Err returns the error that ended the event stream, if any.
+func (m *GameManager) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
+
+// Close stops the manager along with all of its sessions, and returns once
+// their streams are closed.
+func (m *GameManager) Close() {
+	m.cancel()
+	<-m.done
+	m.sessions.Wait()
+}
@@ -0,0 +1,23 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+/*
+ * MESSAGES
+ */
+
+// POST
+const inboxPath = "/inbox/%s" // Username
+
+// SendMessage sends a private message to username from the authenticated
+// user. Lichess limits how many messages new accounts and bots may send.
+func (l *Lichess) SendMessage(ctx context.Context, username string, text string) error {
+	if err := l.authorized().requireScope(ScopeMsgWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(inboxPath, url.PathEscape(username)), url.Values{"text": {text}})
+}
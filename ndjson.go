@@ -0,0 +1,144 @@
+package lichess
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+ * NDJSON
+ */
+
+const (
+	defaultMaxBodySize = 16 << 20
+	defaultMaxLineSize = 1 << 20
+)
+
+// ResponseTooLargeError is returned instead of reading a response body, or a
+// single line of a stream, larger than the configured limit.
+type ResponseTooLargeError struct {
+	Limit int64
+	// Line is set when the limit applied to one line of an NDJSON stream
+	Line bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Line {
+		return fmt.Sprintf("lichess: stream line exceeds %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("lichess: response body exceeds %d bytes", e.Limit)
+}
+
+// SetResponseLimits caps the size of non-streaming response bodies and of
+// each line of NDJSON streams, in bytes. Zero selects the defaults (16MiB and
+// 1MiB), a negative value removes the limit.
+func (l *Lichess) SetResponseLimits(maxBody int64, maxLine int) {
+	l.maxBodySize = maxBody
+	l.maxLineSize = maxLine
+}
+
+func (l *Lichess) bodyLimit() int64 {
+	if l.maxBodySize == 0 {
+		return defaultMaxBodySize
+	}
+	return l.maxBodySize
+}
+
+func (l *Lichess) lineLimit() int {
+	if l.maxLineSize == 0 {
+		return defaultMaxLineSize
+	}
+	return l.maxLineSize
+}
+
+// readBody reads all of r, failing once more than limit bytes were read.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, &ResponseTooLargeError{Limit: limit}
+	}
+	return body, nil
+}
+
+// ndjsonReader splits a newline delimited JSON stream into its values,
+// skipping the blank keep-alive lines Lichess sends on idle streams.
+type ndjsonReader struct {
+	r *bufio.Reader
+	maxLine int
+	buf []byte
+}
+
+func newNDJSONReader(r io.Reader, maxLine int) *ndjsonReader {
+	return &ndjsonReader{r: bufio.NewReader(r), maxLine: maxLine}
+}
+
+// Next returns the next non-blank line. It is only valid until the next call.
+func (nr *ndjsonReader) Next() ([]byte, error) {
+	for {
+		line, err := nr.readLine()
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+func (nr *ndjsonReader) readLine() ([]byte, error) {
+	nr.buf = nr.buf[:0]
+	for {
+		chunk, err := nr.r.ReadSlice('\n')
+		if nr.maxLine > 0 && len(nr.buf)+len(chunk) > nr.maxLine {
+			return nil, &ResponseTooLargeError{Limit: int64(nr.maxLine), Line: true}
+		}
+		nr.buf = append(nr.buf, chunk...)
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(nr.buf) > 0:
+			return nr.buf, nil
+		case err != nil:
+			return nil, err
+		}
+		return nr.buf, nil
+	}
+}
+
+// Decode reads the next value of the stream into v, returning io.EOF at the
+// end of the stream and a *StreamError for error lines.
+func (nr *ndjsonReader) Decode(v interface{}) error {
+	line, err := nr.Next()
+	if err != nil {
+		return err
+	}
+	if err := streamError(line); err != nil {
+		return err
+	}
+	return json.Unmarshal(line, v)
+}
+
+// streamError detects the error objects some streams send in place of a
+// value. The cheap search keeps most regular lines from being decoded twice.
+func streamError(line []byte) error {
+	if !bytes.Contains(line, []byte(`"error"`)) {
+		return nil
+	}
+	var v struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(line, &v) != nil || v.Error == "" {
+		return nil
+	}
+	return &StreamError{Message: v.Error}
+}
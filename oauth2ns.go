@@ -2,28 +2,25 @@ package lichess
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
-	"path"
-	"encoding/json"
 
-	"github.com/fatih/color"
 	rndm "github.com/nmrshll/rndm-go"
 	"github.com/palantir/stacktrace"
 	"github.com/skratchdot/open-golang/open"
-	"github.com/99designs/keyring"
-	// "github.com/jinzhu/copier"
 	"golang.org/x/oauth2"
 )
 
 type AuthorizedClient struct {
 	*http.Client
 	Token *oauth2.Token
+
+	scopesMu sync.RWMutex
+	scopes []Scope
 }
 
 const (
@@ -43,6 +40,26 @@ const (
 type AuthenticateUserOption func(*AuthenticateUserFuncConfig) error
 type AuthenticateUserFuncConfig struct {
 	AuthCallHTTPParams url.Values
+	// Port the local callback server listens on, PORT by default
+	Port int
+	// RedirectURI overrides the callback URL registered with Lichess, e.g.
+	// when the callback goes through a tunnel or reverse proxy. Its path is
+	// served by the local callback server.
+	RedirectURI string
+	// Timeout after which authentication is cancelled
+	Timeout time.Duration
+	// NoBrowser only prints the authorization URL instead of opening it
+	NoBrowser bool
+	// Transport tunes the connection pool of the returned client
+	Transport TransportConfig
+	// TokenStore keeps the token between runs, the OS keyring by default
+	TokenStore TokenStore
+	// PKCE secures the code exchange with a code verifier (RFC 7636) instead
+	// of a client secret
+	PKCE bool
+	// Logger receives the progress of the login, including the URL to open,
+	// nothing is logged by default
+	Logger Logger
 }
 
 func WithAuthCallHTTPParams(values url.Values) AuthenticateUserOption {
@@ -52,107 +69,226 @@ func WithAuthCallHTTPParams(values url.Values) AuthenticateUserOption {
 	}
 }
 
-// AuthenticateUser starts the login process
-func AuthenticateUser(oauthConfig *oauth2.Config, options ...AuthenticateUserOption) (*AuthorizedClient, error) {
+func WithPort(port int) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.Port = port
+		return nil
+	}
+}
+
+func WithRedirectURI(uri string) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.RedirectURI = uri
+		return nil
+	}
+}
+
+func WithAuthTimeout(timeout time.Duration) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.Timeout = timeout
+		return nil
+	}
+}
+
+func WithTransportConfig(transport TransportConfig) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.Transport = transport
+		return nil
+	}
+}
+
+// WithPKCE enables the PKCE flow, the only one Lichess supports for apps
+// without a client secret.
+func WithPKCE() AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.PKCE = true
+		return nil
+	}
+}
+
+// WithTokenStore persists the token in store instead of the OS keyring.
+func WithTokenStore(store TokenStore) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.TokenStore = store
+		return nil
+	}
+}
+
+// WithAuthLogger logs the progress of the login to logger. The clients
+// authenticated by a Lichess use its logger by default.
+func WithAuthLogger(logger Logger) AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.Logger = logger
+		return nil
+	}
+}
+
+// WithoutBrowser is meant for headless machines, the authorization URL is
+// logged at the info level to be opened elsewhere.
+func WithoutBrowser() AuthenticateUserOption {
+	return func(conf *AuthenticateUserFuncConfig) error {
+		conf.NoBrowser = true
+		return nil
+	}
+}
+
+// AuthenticateUser starts the login process, waiting for the user to
+// authorize the app until the timeout option expires or ctx is done. A token
+// saved in the token store by an earlier run is reused as long as it is
+// valid or can be refreshed, refreshed tokens are saved back.
+func AuthenticateUser(ctx context.Context, oauthConfig *oauth2.Config, options ...AuthenticateUserOption) (*AuthorizedClient, error) {
 	// validate params
 	if oauthConfig == nil {
 		return nil, stacktrace.NewError("oauthConfig can't be nil")
 	}
 	// read options
-	var optionsConfig AuthenticateUserFuncConfig
+	optionsConfig := AuthenticateUserFuncConfig{
+		Port: PORT,
+		Timeout: authTimeout * time.Second,
+		Transport: DefaultTransportConfig,
+	}
 	for _, processConfigFunc := range options {
-		processConfigFunc(&optionsConfig)
+		if err := processConfigFunc(&optionsConfig); err != nil {
+			return nil, stacktrace.Propagate(err, "invalid option")
+		}
 	}
 
-	// add transport for self-signed certificate to context
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	sslcli := &http.Client{Transport: tr}
+	logger := loggerOrNop(optionsConfig.Logger)
+
+	// the token exchange, refreshes and API requests all go through this
+	// transport, with the default certificate checks
+	sslcli := &http.Client{Transport: NewTransport(optionsConfig.Transport)}
 
-	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, sslcli)
+	// the client outlives ctx, which only bounds the login itself
+	login := ctx
+	ctx = context.WithValue(context.WithoutCancel(ctx), oauth2.HTTPClient, sslcli)
 	oauthStateString := rndm.String(8)
 
-	if isAuthorized() {
-		token := getAuthFromKeyring()
-		ctx = context.WithValue(ctx, oauthStateStringContextKey, oauthStateString)
+	store := optionsConfig.TokenStore
+	if store == nil {
+		store = NewKeyringTokenStore(serviceName)
+	}
+	if token, err := store.Load(); err == nil && (token.Valid() || token.RefreshToken != "") {
+		return newStoredClient(ctx, oauthConfig, store, token), nil
+	}
 
-		client := &AuthorizedClient{
-			oauthConfig.Client(ctx, &token),
-			&token,
-		}
+	// Redirect user to consent page to ask for permission
+	// for the scopes specified above.
+	oauthConfig.RedirectURL = fmt.Sprintf("http://%s:%s/oauth/callback", IP, strconv.Itoa(optionsConfig.Port))
+	if optionsConfig.RedirectURI != "" {
+		oauthConfig.RedirectURL = optionsConfig.RedirectURI
+	}
+	redirectURL, err := url.Parse(oauthConfig.RedirectURL)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed parsing redirect uri")
+	}
+	ctx = context.WithValue(ctx, oauthStateStringContextKey, oauthStateString)
+	authOptions := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+	verifier := ""
+	if optionsConfig.PKCE {
+		verifier = oauth2.GenerateVerifier()
+		authOptions = append(authOptions, oauth2.S256ChallengeOption(verifier))
+	}
+	urlString := oauthConfig.AuthCodeURL(oauthStateString, authOptions...)
 
-		return client, nil
-	} else {
-		// Redirect user to consent page to ask for permission
-		// for the scopes specified above.
-		oauthConfig.RedirectURL = fmt.Sprintf("http://%s:%s/oauth/callback", IP, strconv.Itoa(PORT))
-		ctx = context.WithValue(ctx, oauthStateStringContextKey, oauthStateString)
-		urlString := oauthConfig.AuthCodeURL(oauthStateString, oauth2.AccessTypeOffline)
-
-		if optionsConfig.AuthCallHTTPParams != nil {
-			parsedURL, err := url.Parse(urlString)
-			if err != nil {
-				return nil, stacktrace.Propagate(err, "failed parsing url string")
-			}
-			params := parsedURL.Query()
-			for key, value := range optionsConfig.AuthCallHTTPParams {
-				params[key] = value
-			}
-			parsedURL.RawQuery = params.Encode()
-			urlString = parsedURL.String()
+	if optionsConfig.AuthCallHTTPParams != nil {
+		parsedURL, err := url.Parse(urlString)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "failed parsing url string")
 		}
-
-		if IP != "127.0.0.1" {
-			urlString = fmt.Sprintf("%s&device_id=%s&device_name=%s", urlString, DEVICE_NAME, DEVICE_NAME)
+		params := parsedURL.Query()
+		for key, value := range optionsConfig.AuthCallHTTPParams {
+			params[key] = value
 		}
+		parsedURL.RawQuery = params.Encode()
+		urlString = parsedURL.String()
+	}
 
-		clientChan, stopHTTPServerChan, cancelAuthentication := startHTTPServer(ctx, oauthConfig)
-		log.Println(color.CyanString("You will now be taken to your browser for authentication or open the url below in a browser."))
-		log.Println(color.CyanString(urlString))
-		log.Println(color.CyanString("If you are opening the url manually on a different machine you will need to curl the result url on this machine manually."))
+	if IP != "127.0.0.1" {
+		urlString = fmt.Sprintf("%s&device_id=%s&device_name=%s", urlString, DEVICE_NAME, DEVICE_NAME)
+	}
+
+	clientChan, stopHTTPServerChan, cancelAuthentication, serverErr := startHTTPServer(ctx, oauthConfig, verifier, optionsConfig.Port, redirectURL.Path, logger)
+	if optionsConfig.NoBrowser {
+		logger.Info("lichess: open the url in a browser to authenticate", "url", urlString)
+	} else {
+		logger.Info("lichess: opening the browser for authentication, or open the url in a browser", "url", urlString)
+	}
+	logger.Info("lichess: if the url is opened on a different machine, the resulting url must be curled on this machine")
+	if !optionsConfig.NoBrowser {
 		time.Sleep(1000 * time.Millisecond)
 		err := open.Run(urlString)
 		if err != nil {
-			log.Println(color.RedString("Failed to open browser, you MUST do the manual process."))
+			logger.Warn("lichess: failed to open the browser, the url must be opened manually", "error", err)
 		}
 		time.Sleep(600 * time.Millisecond)
+	}
 
-		// shutdown the server after timeout
-		go func() {
-			log.Printf("Authentication will be cancelled in %s seconds", strconv.Itoa(authTimeout))
-			time.Sleep(authTimeout * time.Second)
-			stopHTTPServerChan <- struct{}{}
-		}()
+	// shutdown the server after timeout
+	logger.Debug("lichess: authentication will be cancelled after the timeout", "timeout", optionsConfig.Timeout)
+	timeout := time.AfterFunc(optionsConfig.Timeout, func() {
+		stopHTTPServerChan <- struct{}{}
+	})
 
-		select {
-		// wait for client on clientChan
-		case client := <-clientChan:
-			// After the callbackHandler returns a client, it's time to shutdown the server gracefully
+	select {
+	// wait for client on clientChan
+	case client := <-clientChan:
+		// After the callbackHandler returns a client, it's time to shutdown the server gracefully
+		if timeout.Stop() {
 			stopHTTPServerChan <- struct{}{}
-			return client, nil
+		}
+		if err := store.Save(client.Token); err != nil {
+			logger.Warn("lichess: could not save the token, the next run will authenticate again", "error", err)
+		}
+		return newStoredClient(ctx, oauthConfig, store, client.Token), nil
+
+		// if authentication process is cancelled first return an error
+	case <-cancelAuthentication:
+		return nil, fmt.Errorf("authentication timed out and was cancelled")
+
+	case err := <-serverErr:
+		timeout.Stop()
+		return nil, stacktrace.Propagate(err, "auth server failed")
 
-			// if authentication process is cancelled first return an error
-		case <-cancelAuthentication:
-			return nil, fmt.Errorf("authentication timed out and was cancelled")
+	case <-login.Done():
+		if timeout.Stop() {
+			stopHTTPServerChan <- struct{}{}
 		}
+		return nil, login.Err()
+	}
+}
+
+// NewClientWithToken returns a client authenticated with a personal API
+// token, created on https://lichess.org/account/oauth/token, for bots and
+// scripts that cannot go through the browser. The granted scopes are learned
+// from the first response.
+func NewClientWithToken(token string) *AuthorizedClient {
+	t := &oauth2.Token{AccessToken: token, TokenType: "Bearer"}
+	return &AuthorizedClient{
+		Client: oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(t)),
+		Token: t,
 	}
 }
 
-func startHTTPServer(ctx context.Context, conf *oauth2.Config) (clientChan chan *AuthorizedClient, stopHTTPServerChan chan struct{}, cancelAuthentication chan struct{}) {
+func startHTTPServer(ctx context.Context, conf *oauth2.Config, verifier string, port int, callbackPath string, logger Logger) (clientChan chan *AuthorizedClient, stopHTTPServerChan chan struct{}, cancelAuthentication chan struct{}, serverErr chan error) {
 	// init returns
 	clientChan = make(chan *AuthorizedClient)
-	stopHTTPServerChan = make(chan struct{})
-	cancelAuthentication = make(chan struct{})
+	stopHTTPServerChan = make(chan struct{}, 1)
+	cancelAuthentication = make(chan struct{}, 1)
+	serverErr = make(chan error, 1)
 
-	http.HandleFunc("/oauth/callback", callbackHandler(ctx, conf, clientChan))
-	srv := &http.Server{Addr: ":" + strconv.Itoa(PORT)}
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, callbackHandler(ctx, conf, verifier, clientChan, logger))
+	srv := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux}
 
 	// handle server shutdown signal
 	go func() {
 		// wait for signal on stopHTTPServerChan
 		<-stopHTTPServerChan
-		log.Println("Shutting down server...")
+		logger.Debug("lichess: shutting down the auth server")
 
 		// give it 5 sec to shutdown gracefully, else quit program
 		d := time.Now().Add(5 * time.Second)
@@ -160,7 +296,7 @@ func startHTTPServer(ctx context.Context, conf *oauth2.Config) (clientChan chan
 		defer cancel()
 
 		if err := srv.Shutdown(ctx); err != nil {
-			log.Printf(color.RedString("Auth server could not shutdown gracefully: %v"), err)
+			logger.Warn("lichess: auth server could not shut down gracefully", "error", err)
 		}
 
 		// after server is shutdown, quit program
@@ -170,41 +306,46 @@ func startHTTPServer(ctx context.Context, conf *oauth2.Config) (clientChan chan
 	// handle callback request
 	go func() {
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			logger.Error("lichess: auth server failed", "error", err)
+			serverErr <- err
+			return
 		}
-		fmt.Println("Server gracefully stopped")
+		logger.Debug("lichess: auth server stopped")
 	}()
 
-	return clientChan, stopHTTPServerChan, cancelAuthentication
+	return clientChan, stopHTTPServerChan, cancelAuthentication, serverErr
 }
 
-func callbackHandler(ctx context.Context, oauthConfig *oauth2.Config, clientChan chan *AuthorizedClient) func(w http.ResponseWriter, r *http.Request) {
+// callbackHandler exchanges the authorization code, along with the PKCE
+// verifier if one was generated.
+func callbackHandler(ctx context.Context, oauthConfig *oauth2.Config, verifier string, clientChan chan *AuthorizedClient, logger Logger) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestStateString := ctx.Value(oauthStateStringContextKey).(string)
 		responseStateString := r.FormValue("state")
 		if responseStateString != requestStateString {
-			fmt.Printf("invalid oauth state, expected '%s', got '%s'\n", requestStateString, responseStateString)
+			logger.Warn("lichess: invalid oauth state", "expected", requestStateString, "got", responseStateString)
 			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 			return
 		}
 
 		code := r.FormValue("code")
-		token, err := oauthConfig.Exchange(ctx, code)
+		var exchangeOptions []oauth2.AuthCodeOption
+		if verifier != "" {
+			exchangeOptions = append(exchangeOptions, oauth2.VerifierOption(verifier))
+		}
+		token, err := oauthConfig.Exchange(ctx, code, exchangeOptions...)
 		if err != nil {
-			fmt.Printf("oauthoauthConfig.Exchange() failed with '%s'\n", err)
+			logger.Error("lichess: oauth code exchange failed", "error", err)
 			http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 			return
 		}
 		// The HTTP Client returned by oauthConfig.Client will refresh the token as necessary
 		client := &AuthorizedClient{
-			oauthConfig.Client(ctx, token),
-			token,
+			Client: oauthConfig.Client(ctx, token),
+			Token: token,
+			scopes: tokenScopes(token, oauthConfig.Scopes),
 		}
 
-		// tokenCpy := oauth2.Token{}
-		// copier.Copy(token, &tokenCpy)
-		// createKeyRing(&tokenCpy)
-
 		// show success page
 		successPage := `
 		<div style="height:100px; width:100%!; display:flex; flex-direction: column; justify-content: center; align-items:center; background-color:#2ecc71; color:white; font-size:22"><div>Success!</div></div>
@@ -216,80 +357,3 @@ func callbackHandler(ctx context.Context, oauthConfig *oauth2.Config, clientChan
 		clientChan <- client
 	}
 }
-
-func isAuthorized() bool {
-	ring, _ := keyring.Open(keyring.Config{
-		ServiceName: serviceName,
-	})
-
-	_, err := ring.Get("token")
-	if err != nil {
-		fmt.Println(err)
-		return false
-	}
-	return true
-}
-
-func createKeyRing(token *oauth2.Token) {
-	// ucd, err := os.UserConfigDir()
-	// if err != nil {
-	// 	fmt.Println(err)
-	// 	return
-	// }
-
-	ring, _ := keyring.Open(keyring.Config{
-		ServiceName: serviceName,
-
-		// MacOS keychain
-		KeychainName: serviceName,
-		KeychainTrustApplication: true,
-
-		// KDE Wallet
-		KWalletAppID:  serviceName,
-		KWalletFolder: serviceName,
-
-		// Windows
-		WinCredPrefix: serviceName,
-
-		// freedesktop.org's Secret Service
-		LibSecretCollectionName: serviceName,
-
-		// Pass (https://www.passwordstore.org/)
-		PassPrefix: serviceName,
-
-		// Fallback encrypted file
-		// path.Join(ucd, serviceName, "keyring"),
-		FileDir: path.Join("~", serviceName, "keyring"),
-	})
-
-	tokenJSON, err := json.Marshal(token)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	err = ring.Set(keyring.Item{
-		Key: "token",
-		Data: []byte(tokenJSON),
-	})
-
-	if err != nil {
-		fmt.Println(err)
-	}
-}
-
-func getAuthFromKeyring() (token oauth2.Token) {
-	ring, err := keyring.Open(keyring.Config{
-		ServiceName: serviceName,
-	})
-
-	if err != nil {
-		fmt.Println(err)
-	}
-
-	tokenJSON, _ := ring.Get("token")
-	tokenInter := oauth2.Token{}
-	json.Unmarshal(tokenJSON.Data, &tokenInter)
-
-	return tokenInter
-}
\ No newline at end of file
@@ -0,0 +1,131 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+/*
+ * PERF STATS
+ */
+
+// GET
+const perfStatsPath = "/api/user/%s/perf/%s" // Username, PerfType
+
+// PerfStats are the statistics of a user in one perf, as shown on the perf
+// page of their profile.
+type PerfStats struct {
+	User struct {
+		Name string `json:"name"`
+	} `json:"user"`
+	Perf PerfGlicko `json:"perf"`
+	// Rank is nil for players absent from the leaderboards
+	Rank *int `json:"rank"`
+	// Percentile is the share of players rated below the user, in percent
+	Percentile float64 `json:"percentile"`
+	Stat PerfStat `json:"stat"`
+}
+
+type PerfGlicko struct {
+	Glicko struct {
+		Rating float64 `json:"rating"`
+		Deviation float64 `json:"deviation"`
+		Provisional bool `json:"provisional,omitempty"`
+	} `json:"glicko"`
+	// Nb is the number of rated games
+	Nb int `json:"nb"`
+	// Progress is the rating change over the last 12 games
+	Progress int `json:"progress"`
+}
+
+type PerfStat struct {
+	ID string `json:"id"`
+	UserID LightUser `json:"userId"`
+	PerfType struct {
+		Key string `json:"key"`
+		Name string `json:"name"`
+	} `json:"perfType"`
+	// Highest and Lowest are nil until enough games were played
+	Highest *PerfRatingAt `json:"highest"`
+	Lowest *PerfRatingAt `json:"lowest"`
+	BestWins PerfResults `json:"bestWins"`
+	WorstLosses PerfResults `json:"worstLosses"`
+	Count PerfCount `json:"count"`
+	ResultStreak struct {
+		Win PerfStreaks `json:"win"`
+		Loss PerfStreaks `json:"loss"`
+	} `json:"resultStreak"`
+	PlayStreak PerfPlayStreak `json:"playStreak"`
+}
+
+// PerfRatingAt is a rating reached in a game.
+type PerfRatingAt struct {
+	Rating int `json:"int"`
+	At time.Time `json:"at"`
+	GameID string `json:"gameId"`
+}
+
+type PerfResults struct {
+	Results []PerfResult `json:"results"`
+}
+
+// PerfResult is a game against a rated opponent, the best wins being
+// against the highest rated ones.
+type PerfResult struct {
+	OpRating int `json:"opRating"`
+	OpID LightUser `json:"opId"`
+	At time.Time `json:"at"`
+	GameID string `json:"gameId"`
+}
+
+type PerfCount struct {
+	All int `json:"all"`
+	Rated int `json:"rated"`
+	Win int `json:"win"`
+	Loss int `json:"loss"`
+	Draw int `json:"draw"`
+	Tour int `json:"tour"`
+	Berserk int `json:"berserk"`
+	// OpAvg is the average rating of the opponents
+	OpAvg float64 `json:"opAvg"`
+	// Seconds is the time spent playing
+	Seconds int `json:"seconds"`
+	Disconnects int `json:"disconnects"`
+}
+
+// PerfStreaks holds the current and longest streaks of a kind.
+type PerfStreaks struct {
+	Cur PerfStreak `json:"cur"`
+	Max PerfStreak `json:"max"`
+}
+
+// PerfStreak is V games, or seconds for time streaks, played from one game
+// to another. From and To are nil for empty streaks.
+type PerfStreak struct {
+	V int `json:"v"`
+	From *PerfGameAt `json:"from,omitempty"`
+	To *PerfGameAt `json:"to,omitempty"`
+}
+
+type PerfGameAt struct {
+	At time.Time `json:"at"`
+	GameID string `json:"gameId"`
+}
+
+// PerfPlayStreak counts the games played with less than an hour between
+// them, by number in Nb and by time in Time.
+type PerfPlayStreak struct {
+	Nb PerfStreaks `json:"nb"`
+	Time PerfStreaks `json:"time"`
+	LastDate *time.Time `json:"lastDate,omitempty"`
+}
+
+// GetPerfStats returns the statistics of username in perf, e.g. "blitz" or
+// "chess960".
+func (l *Lichess) GetPerfStats(ctx context.Context, username string, perf string) (PerfStats, error) {
+	stats := PerfStats{}
+	err := l.getJSON(ctx, fmt.Sprintf(perfStatsPath, url.PathEscape(username), url.PathEscape(perf)), &stats)
+	return stats, err
+}
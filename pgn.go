@@ -0,0 +1,327 @@
+package lichess
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+/*
+ * PGN
+ */
+
+// LivePGNWriter rebuilds the PGN of a game in progress from its board events
+// and writes the complete, valid PGN out again after every move.
+//
+// If the underlying writer can be truncated (e.g. an *os.File) its content is
+// replaced on every update, otherwise each snapshot is appended followed by a
+// blank line.
+type LivePGNWriter struct {
+	w io.Writer
+	game GameFull
+	start *chess.Position
+	pos *chess.Position
+	moves []string
+	san []string
+	// clocks holds the mover's remaining time in milliseconds after each
+	// move, or -1 when it was not observed.
+	clocks []int64
+	status string
+	winner string
+}
+
+type truncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+func NewLivePGNWriter(w io.Writer) *LivePGNWriter {
+	return &LivePGNWriter{w: w, start: chess.NewPosition(), pos: chess.NewPosition()}
+}
+
+// NewLivePGNFile returns a LivePGNWriter that atomically replaces the file at
+// path on every update, so readers never observe a partially written PGN.
+func NewLivePGNFile(path string) *LivePGNWriter {
+	return NewLivePGNWriter(atomicFile(path))
+}
+
+type atomicFile string
+
+func (f atomicFile) Write(p []byte) (int, error) {
+	path := string(f)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := tmp.Write(p)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return n, err
+	}
+	return n, nil
+}
+
+// Follow writes the PGN for every event received on ch until it is closed.
+func (pw *LivePGNWriter) Follow(ch <-chan BoardEvent) error {
+	for b := range ch {
+		if err := pw.Update(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update consumes a single board event, writing the PGN if it changed the
+// game. Events other than GameFull and GameState are ignored.
+func (pw *LivePGNWriter) Update(e BoardEvent) error {
+	switch event := e.(type) {
+	case GameFull:
+		start, err := startPosition(string(event.Variant.Key), event.InitialFen)
+		if err != nil {
+			return err
+		}
+		pw.game = event
+		pw.start = start
+		pw.pos = start.Copy()
+		pw.moves, pw.san, pw.clocks = nil, nil, nil
+		state := event.State
+		if err := pw.applyState(state.Moves, state.WhiteTime, state.BlackTime); err != nil {
+			return err
+		}
+		pw.status = string(state.Status)
+		pw.winner = string(state.Winner)
+	case GameState:
+		if err := pw.applyState(event.Moves, event.WhiteTime, event.BlackTime); err != nil {
+			return err
+		}
+		pw.status = string(event.Status)
+		pw.winner = string(event.Winner)
+	default:
+		return nil
+	}
+	return pw.flush()
+}
+
+func (pw *LivePGNWriter) applyState(moves string, wtime, btime time.Duration) error {
+	list := strings.Fields(moves)
+
+	// a takeback or a game picked up again may rewrite the moves already
+	// known, replay them from the start
+	if !hasPrefix(list, pw.moves) {
+		pw.pos = pw.start.Copy()
+		pw.moves, pw.san, pw.clocks = nil, nil, nil
+	}
+
+	for i := len(pw.moves); i < len(list); i++ {
+		m, err := pw.pos.ParseMove(list[i])
+		if err != nil {
+			return err
+		}
+		san, err := pw.pos.SAN(m)
+		if err != nil {
+			return err
+		}
+		pw.pos.Play(m)
+		pw.moves = append(pw.moves, list[i])
+		pw.san = append(pw.san, san)
+		pw.clocks = append(pw.clocks, -1)
+	}
+
+	if n := len(list); n > 0 {
+		// the side that just moved is the one not to move now
+		if pw.pos.Turn() == chess.Black {
+			pw.clocks[n-1] = wtime.Milliseconds()
+		} else {
+			pw.clocks[n-1] = btime.Milliseconds()
+		}
+	}
+	return nil
+}
+
+// hasPrefix reports whether list starts with the moves of prefix.
+func hasPrefix(list []string, prefix []string) bool {
+	if len(list) < len(prefix) {
+		return false
+	}
+	for i, move := range prefix {
+		if list[i] != move {
+			return false
+		}
+	}
+	return true
+}
+
+func (pw *LivePGNWriter) flush() error {
+	pgn := pw.PGN()
+	if t, ok := pw.w.(truncater); ok {
+		if _, err := t.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := t.Truncate(0); err != nil {
+			return err
+		}
+	} else if _, ok := pw.w.(atomicFile); !ok {
+		pgn += "\n"
+	}
+	_, err := io.WriteString(pw.w, pgn)
+	return err
+}
+
+// PGN returns the PGN of the game as currently known.
+func (pw *LivePGNWriter) PGN() string {
+	return formatPGN(pw.game, pw.start, pw.san, pw.clocks, pw.status, pw.winner)
+}
+
+// formatPGN writes the PGN of g, whose SAN moves were played from start.
+// clocks may be nil, see pgnMovetext.
+func formatPGN(g GameFull, start *chess.Position, san []string, clocks []int64, status string, winner string) string {
+	result := pgnResult(status, winner)
+
+	var b strings.Builder
+	tag := func(name, value string) {
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", name, strings.ReplaceAll(value, "\"", "\\\""))
+	}
+
+	rated := "Casual"
+	if g.Rated {
+		rated = "Rated"
+	}
+	event := rated + " game"
+	if speed := string(g.Speed); speed != "" {
+		event = rated + " " + strings.ToUpper(speed[:1]) + speed[1:] + " game"
+	}
+	tag("Event", event)
+	site := "?"
+	if g.ID != "" {
+		site = lichessURL + "/" + g.ID
+	}
+	tag("Site", site)
+	date := "????.??.??"
+	if !g.CreatedAt.IsZero() {
+		date = g.CreatedAt.UTC().Format("2006.01.02")
+	}
+	tag("Date", date)
+	tag("White", pgnPlayerName(g.White))
+	tag("Black", pgnPlayerName(g.Black))
+	tag("Result", result)
+	for _, side := range []struct {
+		color string
+		p Player
+	}{{"White", g.White}, {"Black", g.Black}} {
+		if side.p.Rating > 0 {
+			tag(side.color+"Elo", strconv.Itoa(side.p.Rating))
+		}
+		if side.p.Title != "" {
+			tag(side.color+"Title", side.p.Title)
+		}
+	}
+	if g.Clock.Initial != 0 || g.Clock.Increment != 0 {
+		tag("TimeControl", fmt.Sprintf("%d+%d", g.Clock.Initial/time.Second, g.Clock.Increment/time.Second))
+	} else {
+		tag("TimeControl", "-")
+	}
+	if g.Variant.Key != "" && g.Variant.Key != "standard" {
+		tag("Variant", g.Variant.Name)
+	}
+	if g.InitialFen != "" && g.InitialFen != "startpos" {
+		tag("FEN", g.InitialFen)
+		tag("SetUp", "1")
+	}
+	b.WriteByte('\n')
+
+	b.WriteString(pgnMovetext(start, san, clocks, result))
+	return b.String()
+}
+
+// PGN returns the PGN of a game followed by a GameSession, built from its
+// board events rather than exported from Lichess. Other games have no
+// Position and fail, they can be exported with ExportGameTo.
+func (g Game) PGN() (string, error) {
+	if g.Position == nil {
+		return "", fmt.Errorf("lichess: game %s is not followed by a session", g.ID)
+	}
+	return g.Position.PGN(), nil
+}
+
+func pgnPlayerName(p Player) string {
+	switch {
+	case p.IsAI():
+		return fmt.Sprintf("lichess AI level %d", p.AILevel)
+	case p.Name == "":
+		return "?"
+	}
+	return p.Name
+}
+
+// pgnResult maps a Lichess game status and winner to a PGN result token.
+func pgnResult(status string, winner string) string {
+	switch winner {
+	case "white":
+		return "1-0"
+	case "black":
+		return "0-1"
+	}
+	if isOngoing(status) || status == "aborted" || status == "noStart" {
+		return "*"
+	}
+	// every other ending without a winner is a draw, timeouts against
+	// insufficient material included
+	return "1/2-1/2"
+}
+
+// pgnMovetext formats SAN moves played from start, annotating each move
+// with its %clk comment when clocks[i] is known, wrapped at 80 columns.
+func pgnMovetext(start *chess.Position, san []string, clocks []int64, result string) string {
+	var tokens []string
+	number := start.MoveNumber()
+	black := start.Turn() == chess.Black
+	for i, m := range san {
+		if !black {
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, m)
+		if i < len(clocks) && clocks[i] >= 0 {
+			tokens = append(tokens, "{ [%clk "+formatClk(clocks[i])+"] }")
+		}
+		if black {
+			number++
+		}
+		black = !black
+	}
+	tokens = append(tokens, result)
+
+	var b strings.Builder
+	width := 0
+	for _, t := range tokens {
+		if width > 0 && width+1+len(t) > 80 {
+			b.WriteByte('\n')
+			width = 0
+		} else if width > 0 {
+			b.WriteByte(' ')
+			width++
+		}
+		b.WriteString(t)
+		width += len(t)
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+func formatClk(ms int64) string {
+	s := ms / 1000
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
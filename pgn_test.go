@@ -0,0 +1,71 @@
+package lichess
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPGNResult(t *testing.T) {
+	tests := []struct {
+		status string
+		winner string
+		want string
+	}{
+		{"started", "", "*"},
+		{"created", "", "*"},
+		{"aborted", "", "*"},
+		{"noStart", "", "*"},
+		{"mate", "white", "1-0"},
+		{"resign", "black", "0-1"},
+		{"outoftime", "white", "1-0"},
+		{"draw", "", "1/2-1/2"},
+		{"stalemate", "", "1/2-1/2"},
+		// time running out against insufficient material
+		{"outoftime", "", "1/2-1/2"},
+		{"timeout", "", "1/2-1/2"},
+		// e.g. racing kings with both kings on the last rank
+		{"variantEnd", "", "1/2-1/2"},
+		{"unknownFinish", "", "1/2-1/2"},
+	}
+	for _, test := range tests {
+		if got := pgnResult(test.status, test.winner); got != test.want {
+			t.Errorf("pgnResult(%q, %q) = %q, want %q", test.status, test.winner, got, test.want)
+		}
+	}
+}
+
+func TestLivePGNWriter(t *testing.T) {
+	var b bytes.Buffer
+	pw := NewLivePGNWriter(&b)
+	full := GameFull{
+		ID: "abcdefgh",
+		State: GameState{Moves: "e2e4", WhiteTime: time.Minute, BlackTime: time.Minute, Status: StatusStarted},
+	}
+	if err := pw.Update(full); err != nil {
+		t.Fatal(err)
+	}
+	if err := pw.Update(GameState{Moves: "e2e4 e7e5", WhiteTime: time.Minute, BlackTime: 50 * time.Second, Status: StatusStarted}); err != nil {
+		t.Fatal(err)
+	}
+	if pgn := pw.PGN(); !strings.Contains(pgn, "1. e4 { [%clk 0:01:00] } e5 { [%clk 0:00:50] } *") {
+		t.Errorf("PGN after two moves:\n%s", pgn)
+	}
+
+	// moves of the same count but another line are replayed from the start
+	if err := pw.Update(GameState{Moves: "d2d4 d7d5", WhiteTime: time.Minute, BlackTime: time.Minute, Status: StatusStarted}); err != nil {
+		t.Fatal(err)
+	}
+	if pgn := pw.PGN(); !strings.Contains(pgn, "1. d4") || strings.Contains(pgn, "e4") {
+		t.Errorf("PGN after the moves changed:\n%s", pgn)
+	}
+
+	if err := pw.Update(GameState{Moves: "d2d4 d7d5", WhiteTime: time.Minute, BlackTime: time.Minute, Status: StatusOutOfTime}); err != nil {
+		t.Fatal(err)
+	}
+	// a plain writer gets each PGN appended, separated by a blank line
+	if pgn := pw.PGN(); !strings.Contains(pgn, `[Result "1/2-1/2"]`) || !strings.HasSuffix(b.String(), pgn+"\n") {
+		t.Errorf("PGN of the finished game:\n%s\nwritten:\n%s", pgn, b.String())
+	}
+}
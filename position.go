@@ -0,0 +1,185 @@
+package lichess
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+/*
+ * POSITION TRACKING
+ */
+
+// PositionTracker follows the position of a game by applying the UCI move
+// lists of its gameFull and gameState events, so the board can be rendered
+// without a chess library of its own. It is safe for concurrent use.
+type PositionTracker struct {
+	mu sync.RWMutex
+	start *chess.Position
+	pos *chess.Position
+	moves []string
+	san []string
+	// captured holds the pieces taken after each move, NoPiece if none
+	captured []chess.Piece
+	// game, status and winner are kept for the PGN
+	game GameFull
+	status string
+	winner string
+}
+
+func NewPositionTracker() *PositionTracker {
+	return &PositionTracker{start: chess.NewPosition(), pos: chess.NewPosition()}
+}
+
+// Follow updates the tracker with every event received on ch until it is
+// closed.
+func (t *PositionTracker) Follow(ch <-chan BoardEvent) error {
+	for e := range ch {
+		if err := t.Update(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update applies the moves of a GameFull or GameState, other events are
+// ignored. A GameFull sets the variant and initial position, and moves taken
+// back are undone.
+func (t *PositionTracker) Update(e BoardEvent) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event := e.(type) {
+	case GameFull:
+		start, err := startPosition(string(event.Variant.Key), event.InitialFen)
+		if err != nil {
+			return err
+		}
+		t.start = start
+		t.reset()
+		t.game = event
+		t.status, t.winner = string(event.State.Status), string(event.State.Winner)
+		return t.play(strings.Fields(event.State.Moves))
+	case GameState:
+		t.status, t.winner = string(event.Status), string(event.Winner)
+		return t.play(strings.Fields(event.Moves))
+	}
+	return nil
+}
+
+// reset must be called with mu held.
+func (t *PositionTracker) reset() {
+	t.pos = t.start.Copy()
+	t.moves, t.san, t.captured = nil, nil, nil
+}
+
+// play brings the position to moves, replaying them from the start unless
+// they extend the ones already played. It must be called with mu held.
+func (t *PositionTracker) play(moves []string) error {
+	prefix := len(t.moves) <= len(moves)
+	for i := 0; prefix && i < len(t.moves); i++ {
+		prefix = t.moves[i] == moves[i]
+	}
+	if !prefix {
+		t.reset()
+	}
+	for _, uci := range moves[len(t.moves):] {
+		m, err := t.pos.ParseMove(uci)
+		if err != nil {
+			return err
+		}
+		san, err := t.pos.SAN(m)
+		if err != nil {
+			return err
+		}
+		captured := capturedPiece(t.pos, m)
+		if err := t.pos.Play(m); err != nil {
+			return err
+		}
+		t.moves = append(t.moves, uci)
+		t.san = append(t.san, san)
+		t.captured = append(t.captured, captured)
+	}
+	return nil
+}
+
+// capturedPiece returns the piece m takes in pos, including en passant, or
+// NoPiece. The pieces blown up around atomic captures are not included.
+func capturedPiece(pos *chess.Position, m chess.Move) chess.Piece {
+	if m.Drop != chess.NoPieceType {
+		return chess.NoPiece
+	}
+	piece, target := pos.PieceAt(m.From), pos.PieceAt(m.To)
+	switch {
+	// castles are encoded as the king taking its own rook
+	case target != chess.NoPiece && target.Color != piece.Color:
+		return target
+	case piece.Type == chess.Pawn && target == chess.NoPiece && m.From.File() != m.To.File():
+		return chess.Piece{Type: chess.Pawn, Color: piece.Color.Other()}
+	}
+	return chess.NoPiece
+}
+
+// Position returns a copy of the current position.
+func (t *PositionTracker) Position() *chess.Position {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pos.Copy()
+}
+
+func (t *PositionTracker) FEN() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pos.FEN()
+}
+
+// Turn returns the side to move.
+func (t *PositionTracker) Turn() Color {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return Color(t.pos.Turn().String())
+}
+
+// MoveNumber returns the fullmove number, 1 before the first move of white.
+func (t *PositionTracker) MoveNumber() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pos.MoveNumber()
+}
+
+// Moves returns the moves played in UCI notation.
+func (t *PositionTracker) Moves() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]string(nil), t.moves...)
+}
+
+// SAN returns the moves played in standard algebraic notation.
+func (t *PositionTracker) SAN() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]string(nil), t.san...)
+}
+
+// Captured returns the pieces of color taken by the opponent so far, in the
+// order they were taken.
+func (t *PositionTracker) Captured(color Color) []chess.Piece {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	pieces := []chess.Piece{}
+	for _, p := range t.captured {
+		if p != chess.NoPiece && p.Color.String() == string(color) {
+			pieces = append(pieces, p)
+		}
+	}
+	return pieces
+}
+
+// PGN returns the PGN of the game so far, its headers taken from the last
+// GameFull.
+func (t *PositionTracker) PGN() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return formatPGN(t.game, t.start, t.san, nil, t.status, t.winner)
+}
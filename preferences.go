@@ -0,0 +1,173 @@
+package lichess
+
+import "strconv"
+
+/*
+ * PREFERENCES
+ */
+
+// Numeric preference modes, with the values Lichess uses on the wire
+
+type AutoQueen uint8
+
+const (
+	AutoQueenNever AutoQueen = 1
+	AutoQueenPremove AutoQueen = 2
+	AutoQueenAlways AutoQueen = 3
+)
+
+func (v AutoQueen) String() string {
+	return prefName(uint8(v), 1, "never", "premove", "always")
+}
+
+type AutoThreefold uint8
+
+const (
+	AutoThreefoldNever AutoThreefold = 1
+	// AutoThreefoldTime claims draws when less than 30 seconds are left
+	AutoThreefoldTime AutoThreefold = 2
+	AutoThreefoldAlways AutoThreefold = 3
+)
+
+func (v AutoThreefold) String() string {
+	return prefName(uint8(v), 1, "never", "time", "always")
+}
+
+// Takeback also applies to the moretime preference.
+type Takeback uint8
+
+const (
+	TakebackNever Takeback = 1
+	TakebackCasual Takeback = 2
+	TakebackAlways Takeback = 3
+)
+
+func (v Takeback) String() string {
+	return prefName(uint8(v), 1, "never", "casual", "always")
+}
+
+type ClockTenths uint8
+
+const (
+	ClockTenthsNever ClockTenths = 0
+	ClockTenthsLowTime ClockTenths = 1
+	ClockTenthsAlways ClockTenths = 2
+)
+
+func (v ClockTenths) String() string {
+	return prefName(uint8(v), 0, "never", "lowTime", "always")
+}
+
+type Animation uint8
+
+const (
+	AnimationNone Animation = 0
+	AnimationFast Animation = 1
+	AnimationNormal Animation = 2
+	AnimationSlow Animation = 3
+)
+
+func (v Animation) String() string {
+	return prefName(uint8(v), 0, "none", "fast", "normal", "slow")
+}
+
+type Coords uint8
+
+const (
+	CoordsNone Coords = 0
+	CoordsInside Coords = 1
+	CoordsOutside Coords = 2
+)
+
+func (v Coords) String() string {
+	return prefName(uint8(v), 0, "none", "inside", "outside")
+}
+
+type Replay uint8
+
+const (
+	ReplayNever Replay = 0
+	ReplaySlow Replay = 1
+	ReplayAlways Replay = 2
+)
+
+func (v Replay) String() string {
+	return prefName(uint8(v), 0, "never", "slow", "always")
+}
+
+// ChallengePref restricts who may challenge the user.
+type ChallengePref uint8
+
+const (
+	ChallengeNever ChallengePref = 1
+	ChallengeRating ChallengePref = 2
+	ChallengeFriends ChallengePref = 3
+	ChallengeRegistered ChallengePref = 4
+	ChallengeAlways ChallengePref = 5
+)
+
+func (v ChallengePref) String() string {
+	return prefName(uint8(v), 1, "never", "rating", "friends", "registered", "always")
+}
+
+// MessagePref restricts who may send private messages to the user.
+type MessagePref uint8
+
+const (
+	MessageNever MessagePref = 1
+	MessageFriends MessagePref = 2
+	MessageAlways MessagePref = 3
+)
+
+func (v MessagePref) String() string {
+	return prefName(uint8(v), 1, "never", "friends", "always")
+}
+
+// SubmitMove is a set of the speeds for which moves must be confirmed.
+type SubmitMove uint8
+
+const (
+	SubmitMoveUnlimited SubmitMove = 1 << iota
+	SubmitMoveCorrespondence
+	SubmitMoveClassical
+	SubmitMoveRapid
+	SubmitMoveBlitz
+)
+
+// Has reports whether moves are confirmed for all speeds of flags.
+func (v SubmitMove) Has(flags SubmitMove) bool {
+	return v&flags == flags
+}
+
+type InsightShare uint8
+
+const (
+	InsightShareNobody InsightShare = 0
+	InsightShareFriends InsightShare = 1
+	InsightShareEverybody InsightShare = 2
+)
+
+func (v InsightShare) String() string {
+	return prefName(uint8(v), 0, "nobody", "friends", "everybody")
+}
+
+type MoveEvent uint8
+
+const (
+	MoveEventClick MoveEvent = 0
+	MoveEventDrag MoveEvent = 1
+	MoveEventBoth MoveEvent = 2
+)
+
+func (v MoveEvent) String() string {
+	return prefName(uint8(v), 0, "click", "drag", "both")
+}
+
+// prefName returns the name of a mode whose values start at first, or the
+// raw number for values Lichess added since.
+func prefName(v uint8, first uint8, names ...string) string {
+	if v < first || int(v-first) >= len(names) {
+		return strconv.Itoa(int(v))
+	}
+	return names[v-first]
+}
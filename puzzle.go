@@ -0,0 +1,301 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+/*
+ * PUZZLES
+ */
+
+// GET
+const puzzlePath = "/api/puzzle/%s" // PuzzleID
+const puzzleActivityPath = "/api/puzzle/activity"
+const nextPuzzlePath = "/api/puzzle/next"
+const puzzleDashboardPath = "/api/puzzle/dashboard/%d" // Days
+const stormDashboardPath = "/api/storm/dashboard/%s" // Username
+
+// POST
+const puzzleRacePath = "/api/racer"
+
+type Puzzle struct {
+	ID string `json:"id"`
+	Rating int `json:"rating"`
+	Plays int `json:"plays"`
+	InitialPly int `json:"initialPly"`
+	Solution []string `json:"solution"`
+	Themes []PuzzleTheme `json:"themes"`
+	// Only set on puzzles from the activity endpoint
+	FEN string `json:"fen,omitempty"`
+	LastMove string `json:"lastMove,omitempty"`
+}
+
+type PuzzleGame struct {
+	ID string `json:"id"`
+	Perf PuzzlePerf `json:"perf"`
+	Rated bool `json:"rated"`
+	Players []PuzzlePlayer `json:"players"`
+	PGN string `json:"pgn"`
+	Clock string `json:"clock"`
+}
+
+type PuzzlePerf struct {
+	Key string `json:"key"`
+	Name string `json:"name"`
+}
+
+type PuzzlePlayer struct {
+	UserID string `json:"userId"`
+	Name string `json:"name"`
+	Color string `json:"color"`
+}
+
+// PuzzleAndGame is a puzzle along with the game it was generated from.
+type PuzzleAndGame struct {
+	Game PuzzleGame `json:"game"`
+	Puzzle Puzzle `json:"puzzle"`
+}
+
+type PuzzleActivity struct {
+	Date uint64 `json:"date"`
+	Puzzle Puzzle `json:"puzzle"`
+	Win bool `json:"win"`
+}
+
+func (l *Lichess) GetPuzzle(ctx context.Context, id string) (PuzzleAndGame, error) {
+	puzzle := PuzzleAndGame{}
+	err := l.getJSON(ctx, fmt.Sprintf(puzzlePath, url.PathEscape(id)), &puzzle)
+	return puzzle, err
+}
+
+// PuzzleDifficulty shifts the rating of the puzzles served relative to the
+// user's puzzle rating.
+type PuzzleDifficulty string
+
+const (
+	DifficultyEasiest PuzzleDifficulty = "easiest"
+	DifficultyEasier PuzzleDifficulty = "easier"
+	DifficultyNormal PuzzleDifficulty = "normal"
+	DifficultyHarder PuzzleDifficulty = "harder"
+	DifficultyHardest PuzzleDifficulty = "hardest"
+)
+
+// GetNextPuzzle returns a new puzzle for the authenticated user, or for an
+// anonymous one. angle is a theme or an opening key, an empty angle and
+// difficulty select any puzzle at the normal difficulty.
+func (l *Lichess) GetNextPuzzle(ctx context.Context, angle PuzzleTheme, difficulty PuzzleDifficulty) (PuzzleAndGame, error) {
+	v := url.Values{}
+	if angle != "" {
+		v.Set("angle", string(angle))
+	}
+	if difficulty != "" {
+		v.Set("difficulty", string(difficulty))
+	}
+	puzzle := PuzzleAndGame{}
+	err := l.getJSON(ctx, withQuery(nextPuzzlePath, v), &puzzle)
+	return puzzle, err
+}
+
+// GetPuzzleActivity returns the most recent puzzles attempted by the
+// authenticated user, newest first. A max of 0 returns the whole history.
+func (l *Lichess) GetPuzzleActivity(ctx context.Context, max int) ([]PuzzleActivity, error) {
+	stream := l.StreamPuzzleActivity(ctx, max, time.Time{})
+	activity := []PuzzleActivity{}
+	for entry := range stream.Events() {
+		activity = append(activity, entry)
+	}
+	if err := <-stream.Errors(); err != nil {
+		return activity, err
+	}
+	return activity, ctx.Err()
+}
+
+// StreamPuzzleActivity streams the puzzles attempted by the authenticated
+// user before the given time, newest first, without holding them in memory.
+// A max of 0 and a zero before go through the whole history.
+func (l *Lichess) StreamPuzzleActivity(ctx context.Context, max int, before time.Time) *Stream[PuzzleActivity] {
+	if err := l.authorized().requireScope(ScopePuzzleRead); err != nil {
+		return failedStream[PuzzleActivity](err)
+	}
+	v := url.Values{}
+	if max > 0 {
+		v.Set("max", strconv.Itoa(max))
+	}
+	if !before.IsZero() {
+		v.Set("before", strconv.FormatInt(before.UnixMilli(), 10))
+	}
+	return newStream(ctx, l, streamSpec[PuzzleActivity]{path: withQuery(puzzleActivityPath, v)})
+}
+
+// PuzzleResults sums up the puzzles solved over a period.
+type PuzzleResults struct {
+	Nb int `json:"nb"`
+	FirstWins int `json:"firstWins"`
+	ReplayWins int `json:"replayWins"`
+	PuzzleRatingAvg int `json:"puzzleRatingAvg"`
+	Performance int `json:"performance"`
+}
+
+type PuzzleThemeResults struct {
+	// Theme is the name of the theme, e.g. "Fork"
+	Theme string `json:"theme"`
+	Results PuzzleResults `json:"results"`
+}
+
+type PuzzleDashboard struct {
+	Days int `json:"days"`
+	Global PuzzleResults `json:"global"`
+	Themes map[PuzzleTheme]PuzzleThemeResults `json:"themes"`
+}
+
+// GetPuzzleDashboard returns how the authenticated user did on puzzles over
+// the last days, overall and by theme.
+func (l *Lichess) GetPuzzleDashboard(ctx context.Context, days int) (PuzzleDashboard, error) {
+	if err := l.authorized().requireScope(ScopePuzzleRead); err != nil {
+		return PuzzleDashboard{}, err
+	}
+	dashboard := PuzzleDashboard{}
+	err := l.getJSON(ctx, fmt.Sprintf(puzzleDashboardPath, days), &dashboard)
+	return dashboard, err
+}
+
+type StormHighScores struct {
+	AllTime int `json:"allTime"`
+	Day int `json:"day"`
+	Week int `json:"week"`
+	Month int `json:"month"`
+}
+
+// StormDay sums up the Puzzle Storm runs of a day.
+type StormDay struct {
+	// Date is e.g. "2024/3/14"
+	Date string `json:"_id"`
+	Runs int `json:"runs"`
+	Score int `json:"score"`
+	Moves int `json:"moves"`
+	Errors int `json:"errors"`
+	Combo int `json:"combo"`
+	// Time is the time played in seconds
+	Time int `json:"time"`
+	Highest int `json:"highest"`
+}
+
+type StormDashboard struct {
+	High StormHighScores `json:"high"`
+	Days []StormDay `json:"days"`
+}
+
+// GetStormDashboard returns the Puzzle Storm high scores of username and its
+// runs over the last days, 30 if days is zero.
+func (l *Lichess) GetStormDashboard(ctx context.Context, username string, days int) (StormDashboard, error) {
+	path := fmt.Sprintf(stormDashboardPath, url.PathEscape(username))
+	if days > 0 {
+		path += "?days=" + strconv.Itoa(days)
+	}
+	dashboard := StormDashboard{}
+	err := l.getJSON(ctx, path, &dashboard)
+	return dashboard, err
+}
+
+// PuzzleRace is a Puzzle Racer race, players join it through URL.
+type PuzzleRace struct {
+	ID string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreatePuzzleRace creates a private race, owned by the authenticated user
+// who starts it from the race page.
+func (l *Lichess) CreatePuzzleRace(ctx context.Context) (PuzzleRace, error) {
+	if err := l.authorized().requireScope(ScopeRacerWrite); err != nil {
+		return PuzzleRace{}, err
+	}
+	race := PuzzleRace{}
+	err := l.postFormJSON(ctx, puzzleRacePath, nil, &race)
+	return race, err
+}
+
+// ExportFailedPuzzles fetches every puzzle that was failed in activity and
+// writes them to w as a multi-game PGN, one chapter per puzzle, starting at
+// the puzzle position with the solution as the main line. The result can be
+// imported into a study or any chess GUI.
+func (l *Lichess) ExportFailedPuzzles(ctx context.Context, activity []PuzzleActivity, w io.Writer) error {
+	seen := map[string]bool{}
+	for _, entry := range activity {
+		if entry.Win || seen[entry.Puzzle.ID] {
+			continue
+		}
+		seen[entry.Puzzle.ID] = true
+
+		puzzle, err := l.GetPuzzle(ctx, entry.Puzzle.ID)
+		if err != nil {
+			return err
+		}
+		pgn, err := puzzlePGN(puzzle)
+		if err != nil {
+			return fmt.Errorf("puzzle %s: %w", entry.Puzzle.ID, err)
+		}
+		if _, err := io.WriteString(w, pgn+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func puzzlePGN(p PuzzleAndGame) (string, error) {
+	start := chess.NewPosition()
+	for _, san := range strings.Fields(p.Game.PGN) {
+		if _, err := start.PlaySAN(san); err != nil {
+			return "", err
+		}
+	}
+
+	pos := start.Copy()
+	moves := []string{}
+	for _, uci := range p.Puzzle.Solution {
+		m, err := pos.ParseMove(uci)
+		if err != nil {
+			return "", err
+		}
+		san, _ := pos.SAN(m)
+		pos.Play(m)
+		moves = append(moves, san)
+	}
+
+	var b strings.Builder
+	tag := func(name, value string) {
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", name, strings.ReplaceAll(value, "\"", "\\\""))
+	}
+	tag("Event", "Puzzle "+p.Puzzle.ID)
+	tag("Site", lichessURL+"/training/"+p.Puzzle.ID)
+	for _, player := range p.Game.Players {
+		if player.Color == "white" {
+			tag("White", player.Name)
+		} else if player.Color == "black" {
+			tag("Black", player.Name)
+		}
+	}
+	tag("Result", "*")
+	tag("FEN", start.FEN())
+	tag("SetUp", "1")
+	b.WriteByte('\n')
+
+	fmt.Fprintf(&b, "{ Rating %d", p.Puzzle.Rating)
+	if len(p.Puzzle.Themes) > 0 {
+		names := make([]string, len(p.Puzzle.Themes))
+		for i, t := range p.Puzzle.Themes {
+			names[i] = t.Name()
+		}
+		fmt.Fprintf(&b, ", themes: %s", strings.Join(names, ", "))
+	}
+	b.WriteString(" }\n")
+	b.WriteString(pgnMovetext(start, moves, nil, "*"))
+	return b.String(), nil
+}
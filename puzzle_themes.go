@@ -0,0 +1,170 @@
+package lichess
+
+import "sort"
+
+// PuzzleTheme identifies a puzzle theme, as listed on puzzles and used to
+// pick the angle of the next puzzle.
+type PuzzleTheme string
+
+const (
+	ThemeMix PuzzleTheme = "mix"
+	ThemeAdvancedPawn PuzzleTheme = "advancedPawn"
+	ThemeAdvantage PuzzleTheme = "advantage"
+	ThemeAnastasiaMate PuzzleTheme = "anastasiaMate"
+	ThemeArabianMate PuzzleTheme = "arabianMate"
+	ThemeAttackingF2F7 PuzzleTheme = "attackingF2F7"
+	ThemeAttraction PuzzleTheme = "attraction"
+	ThemeBackRankMate PuzzleTheme = "backRankMate"
+	ThemeBishopEndgame PuzzleTheme = "bishopEndgame"
+	ThemeBodenMate PuzzleTheme = "bodenMate"
+	ThemeCapturingDefender PuzzleTheme = "capturingDefender"
+	ThemeCastling PuzzleTheme = "castling"
+	ThemeClearance PuzzleTheme = "clearance"
+	ThemeCornerMate PuzzleTheme = "cornerMate"
+	ThemeCrushing PuzzleTheme = "crushing"
+	ThemeDefensiveMove PuzzleTheme = "defensiveMove"
+	ThemeDeflection PuzzleTheme = "deflection"
+	ThemeDiscoveredAttack PuzzleTheme = "discoveredAttack"
+	ThemeDoubleBishopMate PuzzleTheme = "doubleBishopMate"
+	ThemeDoubleCheck PuzzleTheme = "doubleCheck"
+	ThemeDovetailMate PuzzleTheme = "dovetailMate"
+	ThemeEndgame PuzzleTheme = "endgame"
+	ThemeEnPassant PuzzleTheme = "enPassant"
+	ThemeEquality PuzzleTheme = "equality"
+	ThemeExposedKing PuzzleTheme = "exposedKing"
+	ThemeFork PuzzleTheme = "fork"
+	ThemeHangingPiece PuzzleTheme = "hangingPiece"
+	ThemeHookMate PuzzleTheme = "hookMate"
+	ThemeInterference PuzzleTheme = "interference"
+	ThemeIntermezzo PuzzleTheme = "intermezzo"
+	ThemeKillBoxMate PuzzleTheme = "killBoxMate"
+	ThemeKingsideAttack PuzzleTheme = "kingsideAttack"
+	ThemeKnightEndgame PuzzleTheme = "knightEndgame"
+	ThemeLong PuzzleTheme = "long"
+	ThemeMaster PuzzleTheme = "master"
+	ThemeMasterVsMaster PuzzleTheme = "masterVsMaster"
+	ThemeMate PuzzleTheme = "mate"
+	ThemeMateIn1 PuzzleTheme = "mateIn1"
+	ThemeMateIn2 PuzzleTheme = "mateIn2"
+	ThemeMateIn3 PuzzleTheme = "mateIn3"
+	ThemeMateIn4 PuzzleTheme = "mateIn4"
+	ThemeMateIn5 PuzzleTheme = "mateIn5"
+	ThemeMiddlegame PuzzleTheme = "middlegame"
+	ThemeOneMove PuzzleTheme = "oneMove"
+	ThemeOpening PuzzleTheme = "opening"
+	ThemePawnEndgame PuzzleTheme = "pawnEndgame"
+	ThemePin PuzzleTheme = "pin"
+	ThemePromotion PuzzleTheme = "promotion"
+	ThemeQueenEndgame PuzzleTheme = "queenEndgame"
+	ThemeQueenRookEndgame PuzzleTheme = "queenRookEndgame"
+	ThemeQueensideAttack PuzzleTheme = "queensideAttack"
+	ThemeQuietMove PuzzleTheme = "quietMove"
+	ThemeRookEndgame PuzzleTheme = "rookEndgame"
+	ThemeSacrifice PuzzleTheme = "sacrifice"
+	ThemeShort PuzzleTheme = "short"
+	ThemeSkewer PuzzleTheme = "skewer"
+	ThemeSmotheredMate PuzzleTheme = "smotheredMate"
+	ThemeSuperGM PuzzleTheme = "superGM"
+	ThemeTrappedPiece PuzzleTheme = "trappedPiece"
+	ThemeUnderPromotion PuzzleTheme = "underPromotion"
+	ThemeVeryLong PuzzleTheme = "veryLong"
+	ThemeVukovicMate PuzzleTheme = "vukovicMate"
+	ThemeXRayAttack PuzzleTheme = "xRayAttack"
+	ThemeZugzwang PuzzleTheme = "zugzwang"
+)
+
+var puzzleThemeNames = map[PuzzleTheme]string{
+	ThemeMix: "Healthy mix",
+	ThemeAdvancedPawn: "Advanced pawn",
+	ThemeAdvantage: "Advantage",
+	ThemeAnastasiaMate: "Anastasia's mate",
+	ThemeArabianMate: "Arabian mate",
+	ThemeAttackingF2F7: "Attacking f2 or f7",
+	ThemeAttraction: "Attraction",
+	ThemeBackRankMate: "Back rank mate",
+	ThemeBishopEndgame: "Bishop endgame",
+	ThemeBodenMate: "Boden's mate",
+	ThemeCapturingDefender: "Capture the defender",
+	ThemeCastling: "Castling",
+	ThemeClearance: "Clearance",
+	ThemeCornerMate: "Corner mate",
+	ThemeCrushing: "Crushing",
+	ThemeDefensiveMove: "Defensive move",
+	ThemeDeflection: "Deflection",
+	ThemeDiscoveredAttack: "Discovered attack",
+	ThemeDoubleBishopMate: "Double bishop mate",
+	ThemeDoubleCheck: "Double check",
+	ThemeDovetailMate: "Dovetail mate",
+	ThemeEndgame: "Endgame",
+	ThemeEnPassant: "En passant",
+	ThemeEquality: "Equality",
+	ThemeExposedKing: "Exposed king",
+	ThemeFork: "Fork",
+	ThemeHangingPiece: "Hanging piece",
+	ThemeHookMate: "Hook mate",
+	ThemeInterference: "Interference",
+	ThemeIntermezzo: "Intermezzo",
+	ThemeKillBoxMate: "Kill box mate",
+	ThemeKingsideAttack: "Kingside attack",
+	ThemeKnightEndgame: "Knight endgame",
+	ThemeLong: "Long puzzle",
+	ThemeMaster: "Master games",
+	ThemeMasterVsMaster: "Master vs Master games",
+	ThemeMate: "Checkmate",
+	ThemeMateIn1: "Mate in 1",
+	ThemeMateIn2: "Mate in 2",
+	ThemeMateIn3: "Mate in 3",
+	ThemeMateIn4: "Mate in 4",
+	ThemeMateIn5: "Mate in 5 or more",
+	ThemeMiddlegame: "Middlegame",
+	ThemeOneMove: "One-move puzzle",
+	ThemeOpening: "Opening",
+	ThemePawnEndgame: "Pawn endgame",
+	ThemePin: "Pin",
+	ThemePromotion: "Promotion",
+	ThemeQueenEndgame: "Queen endgame",
+	ThemeQueenRookEndgame: "Queen and Rook",
+	ThemeQueensideAttack: "Queenside attack",
+	ThemeQuietMove: "Quiet move",
+	ThemeRookEndgame: "Rook endgame",
+	ThemeSacrifice: "Sacrifice",
+	ThemeShort: "Short puzzle",
+	ThemeSkewer: "Skewer",
+	ThemeSmotheredMate: "Smothered mate",
+	ThemeSuperGM: "Super GM games",
+	ThemeTrappedPiece: "Trapped piece",
+	ThemeUnderPromotion: "Underpromotion",
+	ThemeVeryLong: "Very long puzzle",
+	ThemeVukovicMate: "Vukovic mate",
+	ThemeXRayAttack: "X-Ray attack",
+	ThemeZugzwang: "Zugzwang",
+}
+
+// Name returns the English display name of the theme, or its key if the
+// theme is unknown (e.g. an opening angle).
+func (t PuzzleTheme) Name() string {
+	if name, ok := puzzleThemeNames[t]; ok {
+		return name
+	}
+	return string(t)
+}
+
+// Known reports whether t is one of the theme constants.
+func (t PuzzleTheme) Known() bool {
+	_, ok := puzzleThemeNames[t]
+	return ok
+}
+
+func (t PuzzleTheme) String() string {
+	return string(t)
+}
+
+// PuzzleThemes returns every known theme sorted by key.
+func PuzzleThemes() []PuzzleTheme {
+	themes := make([]PuzzleTheme, 0, len(puzzleThemeNames))
+	for t := range puzzleThemeNames {
+		themes = append(themes, t)
+	}
+	sort.Slice(themes, func(i, j int) bool { return themes[i] < themes[j] })
+	return themes
+}
@@ -0,0 +1,118 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+ * RATE LIMITS
+ */
+
+// rateLimitState remembers until when Lichess asked the client to hold its
+// requests.
+type rateLimitState struct {
+	mu sync.Mutex
+	until time.Time
+}
+
+// Clients made without NewLichess share this state.
+var defaultRateLimit rateLimitState
+
+func (s *rateLimitState) cooldown() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Until(s.until)
+}
+
+func (s *rateLimitState) extend(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := time.Now().Add(d); until.After(s.until) {
+		s.until = until
+	}
+}
+
+func (l *Lichess) rateLimit() *rateLimitState {
+	if l.limits != nil {
+		return l.limits
+	}
+	return &defaultRateLimit
+}
+
+// Cooldown returns how long Lichess still wants the client to pause after a
+// 429 response, 0 if requests may be sent.
+func (l *Lichess) Cooldown() time.Duration {
+	if d := l.rateLimit().cooldown(); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// SetRateLimitRetries makes requests rejected with 429 Too Many Requests be
+// retried up to n times. Requests then also wait for the cooldown to end
+// before being sent. The delay doubles on each attempt, starting from the
+// one minute Lichess requires, or its Retry-After if longer. With n = 0,
+// the default, RateLimitError is returned right away.
+func (l *Lichess) SetRateLimitRetries(n int) {
+	l.rateLimitRetries = n
+}
+
+func WithRateLimitRetries(n int) ClientOption {
+	return func(l *Lichess) {
+		l.rateLimitRetries = n
+	}
+}
+
+// sendLimited sends req, waiting out the cooldown and retrying rate limited
+// requests as configured.
+func (l *Lichess) sendLimited(req *http.Request) (*http.Response, error) {
+	limits := l.rateLimit()
+	for attempt := 0; ; attempt++ {
+		if l.rateLimitRetries > 0 {
+			if err := sleepCtx(req.Context(), limits.cooldown()); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := l.send(req)
+		var limited *RateLimitError
+		if !errors.As(err, &limited) {
+			return resp, err
+		}
+		delay := limited.RetryAfter << attempt
+		limits.extend(delay)
+		if attempt >= l.rateLimitRetries || (req.Body != nil && req.GetBody == nil) {
+			l.log().Warn("lichess: rate limited", "path", req.URL.Path, "retryAfter", delay)
+			l.hooks.rateLimited(req, delay, false)
+			return nil, err
+		}
+		l.hooks.rateLimited(req, delay, true)
+		l.log().Debug("lichess: rate limited, retrying", "path", req.URL.Path, "delay", delay)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// sleepCtx waits for d, returning early with the context error when ctx is
+// done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
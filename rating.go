@@ -0,0 +1,72 @@
+package lichess
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+ * RATING RANGE
+ */
+
+// Lichess only accepts rating ranges within these bounds
+const (
+	MinRating = 400
+	MaxRating = 4000
+)
+
+// RatingRange restricts the opponents of a seek or challenge. The zero value
+// accepts any rating.
+type RatingRange struct {
+	Min int
+	Max int
+}
+
+// ParseRatingRange parses the "min-max" form used by Lichess.
+func ParseRatingRange(s string) (RatingRange, error) {
+	min, max, ok := strings.Cut(s, "-")
+	if !ok {
+		return RatingRange{}, fmt.Errorf("lichess: invalid rating range %q", s)
+	}
+	r := RatingRange{}
+	var err error
+	if r.Min, err = strconv.Atoi(strings.TrimSpace(min)); err != nil {
+		return RatingRange{}, fmt.Errorf("lichess: invalid rating range %q", s)
+	}
+	if r.Max, err = strconv.Atoi(strings.TrimSpace(max)); err != nil {
+		return RatingRange{}, fmt.Errorf("lichess: invalid rating range %q", s)
+	}
+	return r, r.Validate()
+}
+
+func (r RatingRange) IsZero() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Validate reports ranges that Lichess would ignore, which would silently
+// turn the seek into an unrestricted one.
+func (r RatingRange) Validate() error {
+	switch {
+	case r.IsZero():
+		return nil
+	case r.Min < MinRating || r.Max > MaxRating:
+		return fmt.Errorf("lichess: rating range %d-%d outside of %d-%d", r.Min, r.Max, MinRating, MaxRating)
+	case r.Min > r.Max:
+		return fmt.Errorf("lichess: rating range %d-%d is empty", r.Min, r.Max)
+	}
+	return nil
+}
+
+// Contains reports whether rating is within the range.
+func (r RatingRange) Contains(rating int) bool {
+	return r.IsZero() || (rating >= r.Min && rating <= r.Max)
+}
+
+// String formats the range as "min-max", or "" for the zero value.
+func (r RatingRange) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
@@ -0,0 +1,54 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+/*
+ * RELATIONS
+ */
+
+// GET
+const followingPath = "/api/rel/following"
+
+// POST
+const followPath = "/api/rel/follow/%s" // Username
+const unfollowPath = "/api/rel/unfollow/%s" // Username
+const blockPath = "/api/rel/block/%s" // Username
+const unblockPath = "/api/rel/unblock/%s" // Username
+
+// GetFollowing streams the profiles of the users followed by the
+// authenticated user.
+func (l *Lichess) GetFollowing(ctx context.Context) *Stream[Profile] {
+	if err := l.authorized().requireScope(ScopeFollowRead); err != nil {
+		return failedStream[Profile](err)
+	}
+	return newStream(ctx, l, streamSpec[Profile]{path: followingPath})
+}
+
+func (l *Lichess) Follow(ctx context.Context, username string) error {
+	return l.relate(ctx, followPath, username)
+}
+
+func (l *Lichess) Unfollow(ctx context.Context, username string) error {
+	return l.relate(ctx, unfollowPath, username)
+}
+
+// Block prevents username from challenging or messaging the authenticated
+// user.
+func (l *Lichess) Block(ctx context.Context, username string) error {
+	return l.relate(ctx, blockPath, username)
+}
+
+func (l *Lichess) Unblock(ctx context.Context, username string) error {
+	return l.relate(ctx, unblockPath, username)
+}
+
+func (l *Lichess) relate(ctx context.Context, path string, username string) error {
+	if err := l.authorized().requireScope(ScopeFollowWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(path, url.PathEscape(username)), nil)
+}
@@ -0,0 +1,299 @@
+package lichess
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (l *Lichess) httpClient() *http.Client {
+	client := l.transportClient()
+	if l.cache == nil {
+		return client
+	}
+	cached := *client
+	cached.Transport = l.cache.Wrap(client.Transport)
+	return &cached
+}
+
+// transportClient returns the client sending the requests, before caching.
+func (l *Lichess) transportClient() *http.Client {
+	client := l.authorized()
+	switch {
+	case client != nil && client.Client != nil && l.baseClient != nil:
+		return l.authorizedHTTP(client)
+	case client != nil && client.Client != nil:
+		return client.Client
+	case l.baseClient != nil:
+		return l.baseClient
+	}
+	return http.DefaultClient
+}
+
+func (l *Lichess) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, l.endpoint(path), body)
+	if err != nil {
+		return nil, err
+	}
+	if l.userAgent != "" {
+		req.Header.Set("User-Agent", l.userAgent)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return req, nil
+}
+
+// do sends the request and returns an error for any non 2xx response. On
+// success the caller is responsible for closing the response body.
+func (l *Lichess) do(req *http.Request) (*http.Response, error) {
+	return l.sendLimited(req)
+}
+
+// send implements do for a single attempt.
+func (l *Lichess) send(req *http.Request) (*http.Response, error) {
+	l.hooks.request(req)
+	start := time.Now()
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		l.hooks.response(req, nil, start, err)
+		return nil, err
+	}
+	l.authorized().recordScopes(resp)
+	err = checkResponse(resp)
+	l.hooks.response(req, resp, start, err)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DefaultMaintenanceBackoff is a schedule suited to riding out a Lichess
+// maintenance window, which usually lasts a few minutes.
+var DefaultMaintenanceBackoff = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+}
+
+// SetMaintenanceBackoff makes streams keep reconnecting while Lichess is
+// unavailable, waiting for each delay of schedule in turn (the last one is
+// repeated) or for the server's Retry-After if it is longer. A nil schedule
+// makes streams fail with ErrServiceUnavailable right away.
+func (l *Lichess) SetMaintenanceBackoff(schedule []time.Duration) {
+	l.maintenanceBackoff = schedule
+}
+
+// openStream opens a long-lived streaming request, waiting out maintenance
+// windows according to the configured backoff schedule. A non-nil body is
+// POSTed as plain text.
+func (l *Lichess) openStream(ctx context.Context, path string, body *string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := l.newStreamRequest(ctx, path, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/x-ndjson")
+
+		resp, err := l.do(req)
+		if err == nil {
+			return resp, nil
+		}
+		var unavailable *ServiceUnavailableError
+		if !errors.As(err, &unavailable) || len(l.maintenanceBackoff) == 0 {
+			return nil, err
+		}
+
+		delay := l.maintenanceBackoff[len(l.maintenanceBackoff)-1]
+		if attempt < len(l.maintenanceBackoff) {
+			delay = l.maintenanceBackoff[attempt]
+		}
+		if unavailable.RetryAfter > delay {
+			delay = unavailable.RetryAfter
+		}
+		l.log().Info("lichess: service unavailable, waiting to reopen the stream", "path", path, "delay", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *Lichess) newStreamRequest(ctx context.Context, path string, body *string) (*http.Request, error) {
+	if body == nil {
+		return l.newRequest(ctx, http.MethodGet, path, nil)
+	}
+	req, err := l.newRequest(ctx, http.MethodPost, path, strings.NewReader(*body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	return req, nil
+}
+
+func (l *Lichess) getJSON(ctx context.Context, path string, v interface{}) error {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, l.bodyLimit())
+	if err != nil {
+		return err
+	}
+	return l.unmarshal(body, v)
+}
+
+// getText returns the body of a GET request for a text format such as PGN.
+func (l *Lichess) getText(ctx context.Context, path string, accept string) (string, error) {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := l.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, l.bodyLimit())
+	return string(body), err
+}
+
+// copyTo streams the response body of req into w without buffering it.
+func (l *Lichess) copyTo(req *http.Request, w io.Writer) (int64, error) {
+	resp, err := l.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return io.Copy(w, resp.Body)
+}
+
+// sendJSON sends in as the JSON body of a request for method and decodes the
+// response into v. Either may be nil, for requests without a body or whose
+// response is discarded.
+func (l *Lichess) sendJSON(ctx context.Context, method string, path string, in interface{}, v interface{}) error {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := l.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	data, err := readBody(resp.Body, l.bodyLimit())
+	if err != nil {
+		return err
+	}
+	return l.unmarshal(data, v)
+}
+
+// postTextJSON POSTs text as plain text, e.g. a list of IDs, and decodes
+// the response into v.
+func (l *Lichess) postTextJSON(ctx context.Context, path string, text string, v interface{}) error {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	req, err := l.newRequest(ctx, http.MethodPost, path, strings.NewReader(text))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := readBody(resp.Body, l.bodyLimit())
+	if err != nil {
+		return err
+	}
+	return l.unmarshal(data, v)
+}
+
+// postForm POSTs values to path and discards the {"ok":true} response.
+func (l *Lichess) postForm(ctx context.Context, path string, values url.Values) error {
+	return l.postFormJSON(ctx, path, values, nil)
+}
+
+// postFormJSON POSTs values to path and decodes the response into v, the
+// response is discarded if v is nil.
+func (l *Lichess) postFormJSON(ctx context.Context, path string, values url.Values, v interface{}) error {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	var body io.Reader
+	if values != nil {
+		body = strings.NewReader(values.Encode())
+	}
+	req, err := l.newRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	data, err := readBody(resp.Body, l.bodyLimit())
+	if err != nil {
+		return err
+	}
+	return l.unmarshal(data, v)
+}
@@ -0,0 +1,92 @@
+package lichess
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ * RESUME
+ */
+
+// reconnectDelays spaces out reconnection attempts of a dropped game stream,
+// the last delay is repeated.
+var reconnectDelays = []time.Duration{
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// MoveCursor remembers the moves of each game already handed to the
+// consumer. When a stream reconnects Lichess starts over with a gameFull
+// event holding the complete move list, the cursor lets only the moves that
+// were not delivered yet through. It is safe for concurrent use.
+type MoveCursor struct {
+	mu sync.Mutex
+	seen map[string][]string
+}
+
+func NewMoveCursor() *MoveCursor {
+	return &MoveCursor{seen: map[string][]string{}}
+}
+
+// Advance records the move list carried by a GameFull or GameState event and
+// returns the moves that were not delivered before. A move list that does
+// not extend the previous one (after a takeback) rewinds the cursor to the
+// moves they share, and yields those played since.
+func (c *MoveCursor) Advance(gameID string, e BoardEvent) []string {
+	var moves string
+	switch event := e.(type) {
+	case GameFull:
+		moves = event.State.Moves
+	case GameState:
+		moves = event.Moves
+	default:
+		return nil
+	}
+	list := strings.Fields(moves)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = map[string][]string{}
+	}
+	seen := c.seen[gameID]
+	c.seen[gameID] = list
+	shared := 0
+	for shared < len(seen) && shared < len(list) && seen[shared] == list[shared] {
+		shared++
+	}
+	if shared == len(list) {
+		return nil
+	}
+	return list[shared:]
+}
+
+// Seen returns the number of moves of the game delivered so far.
+func (c *MoveCursor) Seen(gameID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.seen[gameID])
+}
+
+// Forget drops the game from the cursor.
+func (c *MoveCursor) Forget(gameID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, gameID)
+}
+
+// stateChanged reports whether next has other moves, or another status or
+// winner, than prev.
+func stateChanged(prev GameState, next GameState) bool {
+	return prev.Status != next.Status || prev.Winner != next.Winner ||
+		strings.Join(strings.Fields(prev.Moves), " ") != strings.Join(strings.Fields(next.Moves), " ")
+}
+
+// isOngoing reports whether a game with this status can still receive moves.
+func isOngoing(status string) bool {
+	return status == "" || status == "created" || status == "started"
+}
@@ -0,0 +1,185 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+/*
+ * SCOPES
+ */
+
+type Scope string
+
+const (
+	ScopePreferenceRead Scope = "preference:read"
+	ScopePreferenceWrite Scope = "preference:write"
+	ScopeEmailRead Scope = "email:read"
+	ScopeChallengeRead Scope = "challenge:read"
+	ScopeChallengeWrite Scope = "challenge:write"
+	ScopeChallengeBulk Scope = "challenge:bulk"
+	ScopeStudyRead Scope = "study:read"
+	ScopeStudyWrite Scope = "study:write"
+	ScopeTournamentWrite Scope = "tournament:write"
+	ScopeRacerWrite Scope = "racer:write"
+	ScopePuzzleRead Scope = "puzzle:read"
+	ScopePuzzleWrite Scope = "puzzle:write"
+	ScopeTeamRead Scope = "team:read"
+	ScopeTeamWrite Scope = "team:write"
+	ScopeTeamLead Scope = "team:lead"
+	ScopeFollowRead Scope = "follow:read"
+	ScopeFollowWrite Scope = "follow:write"
+	ScopeMsgWrite Scope = "msg:write"
+	ScopeBoardPlay Scope = "board:play"
+	ScopeBotPlay Scope = "bot:play"
+	ScopeEngineRead Scope = "engine:read"
+	ScopeEngineWrite Scope = "engine:write"
+	ScopeWebLogin Scope = "web:login"
+	ScopeWebMod Scope = "web:mod"
+)
+
+// ErrMissingScope is matched (with errors.Is) by a MissingScopeError.
+var ErrMissingScope = errors.New("lichess: token is missing a required scope")
+
+// ErrNotAuthenticated is matched by the errors of calls needing a token made
+// by an anonymous client, and of the ones rejected by Lichess with 401
+// Unauthorized.
+var ErrNotAuthenticated = errors.New("lichess: not authenticated")
+
+// MissingScopeError is returned before making a call that the token was not
+// granted the scope for.
+type MissingScopeError struct {
+	// Scopes lists the alternatives, any one of them allows the call
+	Scopes []Scope
+	// Anonymous is set when the client has no token at all
+	Anonymous bool
+}
+
+func (e *MissingScopeError) Error() string {
+	names := make([]string, len(e.Scopes))
+	for i, s := range e.Scopes {
+		names[i] = string(s)
+	}
+	if e.Anonymous {
+		return fmt.Sprintf("%v: requires %s", ErrNotAuthenticated, strings.Join(names, " or "))
+	}
+	return fmt.Sprintf("%v: %s", ErrMissingScope, strings.Join(names, " or "))
+}
+
+func (e *MissingScopeError) Is(target error) bool {
+	return target == ErrMissingScope || (e.Anonymous && target == ErrNotAuthenticated)
+}
+
+func parseScopes(s string) []Scope {
+	scopes := []Scope{}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		scopes = append(scopes, Scope(f))
+	}
+	return scopes
+}
+
+// GrantedScopes returns the scopes of the token, or nil if they are not
+// known yet. They are learned from the token exchange and refreshed from the
+// X-OAuth-Scopes header of every API response.
+func (c *AuthorizedClient) GrantedScopes() []Scope {
+	c.scopesMu.RLock()
+	defer c.scopesMu.RUnlock()
+	return c.scopes
+}
+
+func (c *AuthorizedClient) setScopes(scopes []Scope) {
+	c.scopesMu.Lock()
+	c.scopes = scopes
+	c.scopesMu.Unlock()
+}
+
+// HasScope reports whether the token was granted s. It is optimistic and
+// returns true as long as the granted scopes are unknown.
+func (c *AuthorizedClient) HasScope(s Scope) bool {
+	granted := c.GrantedScopes()
+	if granted == nil {
+		return true
+	}
+	for _, g := range granted {
+		if g == s {
+			return true
+		}
+	}
+	return false
+}
+
+// requireScope fails with a *MissingScopeError unless the token has at least
+// one of scopes.
+func (c *AuthorizedClient) requireScope(scopes ...Scope) error {
+	if c == nil {
+		return &MissingScopeError{Scopes: scopes, Anonymous: true}
+	}
+	for _, s := range scopes {
+		if c.HasScope(s) {
+			return nil
+		}
+	}
+	return &MissingScopeError{Scopes: scopes}
+}
+
+func (c *AuthorizedClient) recordScopes(resp *http.Response) {
+	if c == nil {
+		return
+	}
+	if h, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok && len(h) > 0 {
+		c.setScopes(parseScopes(h[0]))
+	}
+}
+
+// tokenScopes returns the scopes granted with token, falling back to the
+// requested ones when the token response did not list them.
+func tokenScopes(token *oauth2.Token, requested []string) []Scope {
+	if s, ok := token.Extra("scope").(string); ok {
+		return parseScopes(s)
+	}
+	if requested == nil {
+		return nil
+	}
+	return parseScopes(strings.Join(requested, " "))
+}
+
+/*
+ * TOKEN TEST
+ */
+
+// POST
+const testTokensPath = "/api/token/test"
+
+// Lichess tests at most this many tokens per TestTokens call.
+const MaxTestTokens = 1000
+
+// TokenInfo describes a valid token.
+type TokenInfo struct {
+	UserID string `json:"userId"`
+	Scopes string `json:"scopes"`
+	// Expires is in milliseconds since the epoch, zero for tokens that
+	// never expire
+	Expires int64 `json:"expires"`
+}
+
+// GrantedScopes returns the scopes of the token.
+func (t TokenInfo) GrantedScopes() []Scope {
+	return parseScopes(t.Scopes)
+}
+
+// TestTokens looks up up to MaxTestTokens tokens at once, e.g. the ones
+// players hand to a bulk pairing organizer. The result is keyed by token,
+// invalid and expired tokens map to nil.
+func (l *Lichess) TestTokens(ctx context.Context, tokens []string) (map[string]*TokenInfo, error) {
+	if len(tokens) > MaxTestTokens {
+		return nil, fmt.Errorf("lichess: at most %d tokens can be tested at once, got %d", MaxTestTokens, len(tokens))
+	}
+	infos := map[string]*TokenInfo{}
+	err := l.postTextJSON(ctx, testTokensPath, strings.Join(tokens, ","), &infos)
+	return infos, err
+}
@@ -0,0 +1,417 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * SEEK
+ */
+
+// ErrSeekTimeout is returned when no opponent accepted a seek within
+// SeekOptions.Timeout.
+var ErrSeekTimeout = errors.New("lichess: no opponent found before the seek timed out")
+
+// SeekOptions describes a game to look for in the lobby. Time is in minutes
+// and Increment in seconds.
+type SeekOptions struct {
+	Rated bool
+	Time int
+	Increment int
+	// Days per move makes a correspondence seek, Time and Increment are then
+	// ignored
+	Days int
+	Variant VariantKey
+	// Color is ColorRandom if empty
+	Color Color
+	RatingRange RatingRange
+	// Timeout stops matchmaking after the given time, 0 waits until ctx is done
+	Timeout time.Duration
+}
+
+func (o SeekOptions) values() url.Values {
+	v := url.Values{}
+	v.Set("rated", strconv.FormatBool(o.Rated))
+	if o.Days > 0 {
+		v.Set("days", strconv.Itoa(o.Days))
+	} else {
+		v.Set("time", strconv.Itoa(o.Time))
+		v.Set("increment", strconv.Itoa(o.Increment))
+	}
+	if o.Variant != "" {
+		v.Set("variant", string(o.Variant))
+	}
+	if o.Color != "" {
+		v.Set("color", string(o.Color))
+	}
+	if !o.RatingRange.IsZero() {
+		v.Set("ratingRange", o.RatingRange.String())
+	}
+	return v
+}
+
+func (o SeekOptions) validate() error {
+	if o.Variant != "" && !o.Variant.Valid() {
+		return fmt.Errorf("lichess: unknown variant %q", o.Variant)
+	}
+	if o.Color != "" && !o.Color.Valid() {
+		return fmt.Errorf("lichess: unknown color %q", o.Color)
+	}
+	return o.RatingRange.Validate()
+}
+
+// Seek is a seek waiting in the lobby, it stays there until it is matched or
+// cancelled.
+type Seek struct {
+	// ID is only given for correspondence seeks
+	ID string
+	correspondence bool
+
+	cancel context.CancelFunc
+	done chan struct{}
+	err error
+}
+
+// Seek posts a seek. The gameStart event of the game follows on the event
+// stream once the seek is matched. Correspondence seeks stay in the lobby on
+// their own: Done is closed as soon as they are posted, and they cannot be
+// cancelled.
+func (l *Lichess) Seek(ctx context.Context, opts SeekOptions) (*Seek, error) {
+	if err := l.authorized().requireScope(ScopeBoardPlay); err != nil {
+		return nil, err
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	var cancel context.CancelFunc
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	s := &Seek{correspondence: opts.Days > 0, cancel: cancel, done: make(chan struct{})}
+	if s.correspondence {
+		defer close(s.done)
+		defer cancel()
+		v := struct {
+			ID string `json:"id"`
+		}{}
+		if err := l.postFormJSON(ctx, seekPath, opts.values(), &v); err != nil {
+			return nil, err
+		}
+		s.ID = v.ID
+		return s, nil
+	}
+	go func() {
+		defer close(s.done)
+		s.err = l.seek(ctx, opts)
+		if s.err == nil && ctx.Err() != nil {
+			s.err = ctx.Err()
+		}
+	}()
+	return s, nil
+}
+
+// Cancel withdraws the seek from the lobby if it was not matched yet.
+func (s *Seek) Cancel() {
+	s.cancel()
+}
+
+// Done is closed once the seek is matched, cancelled or failed.
+func (s *Seek) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the seek ended once Done is closed: nil if it was matched,
+// context.Canceled if it was cancelled.
+func (s *Seek) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Matched reports whether an opponent accepted a real-time seek.
+func (s *Seek) Matched() bool {
+	if s.correspondence {
+		return false
+	}
+	select {
+	case <-s.done:
+		return s.err == nil
+	default:
+		return false
+	}
+}
+
+// seeker is a Seek or a SeekGroup.
+type seeker interface {
+	Done() <-chan struct{}
+	Err() error
+}
+
+// FindAndStartGame seeks a game and returns a session following it once an
+// opponent is found. The seek is withdrawn when ctx is done or the timeout
+// expires, the session itself lives until ctx is done.
+func (l *Lichess) FindAndStartGame(ctx context.Context, opts SeekOptions) (*GameSession, error) {
+	return l.findAndStart(ctx, opts.Timeout, func(ctx context.Context) (seeker, error) {
+		return l.Seek(ctx, opts)
+	})
+}
+
+// FindAndStartAnyGame is FindAndStartGame with the seeks of SeekAll, the
+// first one to be matched starting the game. The search ends once every
+// seek timed out. The games of seeks matched at the same time as the first
+// are aborted, or resigned if the opponent already moved, and logged.
+func (l *Lichess) FindAndStartAnyGame(ctx context.Context, opts ...SeekOptions) (*GameSession, error) {
+	return l.findAndStart(ctx, 0, func(ctx context.Context) (seeker, error) {
+		return l.SeekAll(ctx, opts...)
+	})
+}
+
+func (l *Lichess) findAndStart(ctx context.Context, timeout time.Duration, post func(context.Context) (seeker, error)) (*GameSession, error) {
+	var seekCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		seekCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		seekCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// games already in progress are announced again by the event stream
+	playing, err := l.nowPlaying(seekCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- l.StreamEvents(seekCtx, events)
+	}()
+	seek, err := post(seekCtx)
+	if err != nil {
+		return nil, err
+	}
+	seekDone := seek.Done()
+
+	for {
+		select {
+		case event := <-events:
+			if event.Type != "gameStart" || playing[event.Game.ID] {
+				continue
+			}
+			session := l.newGameSession(ctx, event.Game, true)
+			if group, ok := seek.(*SeekGroup); ok {
+				playing[event.Game.ID] = true
+				l.endExtraGames(seekCtx, group, events, playing)
+			}
+			return session, nil
+		case <-seekDone:
+			if err := seek.Err(); err != nil && seekCtx.Err() == nil {
+				if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+					return nil, ErrSeekTimeout
+				}
+				return nil, err
+			}
+			// the seek response ends when matched, the gameStart follows
+			seekDone = nil
+		case err := <-streamErr:
+			if seekCtx.Err() == nil {
+				if err == nil {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+			streamErr = nil
+		case <-seekCtx.Done():
+			if ctx.Err() == nil {
+				return nil, ErrSeekTimeout
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// extraGamesWait bounds the wait for the gameStart of the seeks matched along
+// with the first one.
+const extraGamesWait = 5 * time.Second
+
+// endExtraGames aborts the games started by the Extra seeks of group, which
+// nobody would play otherwise.
+func (l *Lichess) endExtraGames(ctx context.Context, group *SeekGroup, events <-chan Event, playing map[string]bool) {
+	select {
+	case <-group.Done():
+	case <-ctx.Done():
+		return
+	}
+	timeout := time.After(extraGamesWait)
+	for extra := len(group.Extra()); extra > 0; {
+		select {
+		case event := <-events:
+			if event.Type != "gameStart" || playing[event.Game.ID] {
+				continue
+			}
+			extra--
+			id := event.Game.ID
+			err := l.gameAction(ctx, id, "abort", fmt.Sprintf(abortGamePath, url.PathEscape(id)))
+			if err != nil {
+				err = l.gameAction(ctx, id, "resign", fmt.Sprintf(resignGamePath, url.PathEscape(id)))
+			}
+			l.log().Warn("lichess: ended the game of a seek matched along with another", "game", id, "error", err)
+		case <-timeout:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SeekGroup is a set of real-time seeks kept in the lobby at once, such as
+// several time controls, the others being cancelled as soon as one is
+// matched, like the pools of the lobby.
+type SeekGroup struct {
+	Seeks []*Seek
+
+	cancel context.CancelFunc
+	done chan struct{}
+	// matched holds the seeks matched, first to last
+	matched []*Seek
+	err error
+}
+
+// SeekAll posts a seek for each of opts, each on its own request and with
+// its own timeout. Seeks matched at the same time as the first, before they
+// could be withdrawn, each start a game too: they are reported by Extra and
+// their games are left to the caller.
+func (l *Lichess) SeekAll(ctx context.Context, opts ...SeekOptions) (*SeekGroup, error) {
+	if len(opts) == 0 {
+		return nil, errors.New("lichess: no seek to post")
+	}
+	for _, o := range opts {
+		if o.Days > 0 {
+			return nil, errors.New("lichess: correspondence seeks cannot be grouped")
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	g := &SeekGroup{cancel: cancel, done: make(chan struct{})}
+	for _, o := range opts {
+		seek, err := l.Seek(ctx, o)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		g.Seeks = append(g.Seeks, seek)
+	}
+
+	ended := make(chan *Seek)
+	for _, seek := range g.Seeks {
+		go func(seek *Seek) {
+			<-seek.Done()
+			ended <- seek
+		}(seek)
+	}
+	go func() {
+		defer close(g.done)
+		defer cancel()
+		for range g.Seeks {
+			seek := <-ended
+			switch err := seek.Err(); {
+			case err == nil:
+				if g.matched == nil {
+					cancel()
+				}
+				g.matched = append(g.matched, seek)
+			case err != nil && g.err == nil && !errors.Is(err, context.Canceled):
+				g.err = err
+			}
+		}
+		if g.matched != nil {
+			g.err = nil
+		} else if g.err == nil {
+			g.err = ctx.Err()
+		}
+	}()
+	return g, nil
+}
+
+// Cancel withdraws the seeks not matched yet.
+func (g *SeekGroup) Cancel() {
+	g.cancel()
+}
+
+// Done is closed once a seek was matched and the others withdrawn, or every
+// seek was cancelled or failed.
+func (g *SeekGroup) Done() <-chan struct{} {
+	return g.done
+}
+
+// Err returns nil once Done is closed if a seek was matched, the first
+// failure otherwise, context.Canceled if the group was cancelled.
+func (g *SeekGroup) Err() error {
+	<-g.done
+	return g.err
+}
+
+// Matched returns the seek that found an opponent, nil if none did.
+func (g *SeekGroup) Matched() *Seek {
+	select {
+	case <-g.done:
+		if len(g.matched) > 0 {
+			return g.matched[0]
+		}
+	default:
+	}
+	return nil
+}
+
+// Extra returns the seeks matched along with Matched once Done is closed,
+// each of which started a game of its own.
+func (g *SeekGroup) Extra() []*Seek {
+	select {
+	case <-g.done:
+		if len(g.matched) > 1 {
+			return g.matched[1:]
+		}
+	default:
+	}
+	return nil
+}
+
+// seek posts a real-time seek. Lichess keeps the seek in the lobby as long as
+// the response is being read, so seek only returns once it is matched or ctx
+// is done.
+func (l *Lichess) seek(ctx context.Context, opts SeekOptions) error {
+	req, err := l.newRequest(ctx, http.MethodPost, seekPath, strings.NewReader(opts.values().Encode()))
+	if err != nil {
+		return err
+	}
+	resp, err := l.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+// nowPlaying returns the IDs of the games in progress.
+func (l *Lichess) nowPlaying(ctx context.Context) (map[string]bool, error) {
+	games, err := l.GetOngoingGames(ctx, 50)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, g := range games {
+		ids[g.GameID] = true
+	}
+	return ids, nil
+}
@@ -0,0 +1,140 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seekServer is a lobby matching the seeks of the time controls in match
+// right away and keeping the others open until they are withdrawn.
+type seekServer struct {
+	*httptest.Server
+	match map[string]bool
+	events []string
+
+	mu sync.Mutex
+	withdrawn []string
+	actions []string
+}
+
+func newSeekServer(match map[string]bool, events ...string) *seekServer {
+	s := &seekServer{match: match, events: events}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == seekPath:
+			r.ParseForm()
+			clock := r.PostForm.Get("time")
+			if s.match[clock] {
+				return
+			}
+			<-r.Context().Done()
+			s.mu.Lock()
+			s.withdrawn = append(s.withdrawn, clock)
+			s.mu.Unlock()
+		case r.URL.Path == nowPlayingPath:
+			w.Write([]byte(`{"nowPlaying":[]}`))
+		case r.URL.Path == "/api/stream/event":
+			for _, event := range s.events {
+				w.Write([]byte(event + "\n"))
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case strings.HasPrefix(r.URL.Path, "/api/board/game/stream/"):
+			<-r.Context().Done()
+		case strings.HasPrefix(r.URL.Path, "/api/board/game/"):
+			s.mu.Lock()
+			s.actions = append(s.actions, r.URL.Path)
+			s.mu.Unlock()
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func (s *seekServer) client() *Lichess {
+	l := NewLichess(WithBaseURL(s.URL))
+	l.SetClient(NewClientWithToken("token"))
+	return l
+}
+
+func TestSeekAllCancelsTheRest(t *testing.T) {
+	srv := newSeekServer(map[string]bool{"3": true})
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	g, err := srv.client().SeekAll(ctx, SeekOptions{Time: 5}, SeekOptions{Time: 3}, SeekOptions{Time: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Matched() != g.Seeks[1] || len(g.Extra()) != 0 {
+		t.Errorf("matched %p with %d extra, want the 3 minutes seek %p alone", g.Matched(), len(g.Extra()), g.Seeks[1])
+	}
+	for _, i := range []int{0, 2} {
+		if err := g.Seeks[i].Err(); !errors.Is(err, context.Canceled) {
+			t.Errorf("seek %d ended with %v, want it withdrawn", i, err)
+		}
+	}
+	srv.Close()
+	if len(srv.withdrawn) != 2 {
+		t.Errorf("withdrawn seeks %v, want 5 and 10", srv.withdrawn)
+	}
+}
+
+func TestSeekAllTimedOut(t *testing.T) {
+	srv := newSeekServer(nil)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	opts := []SeekOptions{{Time: 3, Timeout: 50 * time.Millisecond}, {Time: 5, Timeout: 100 * time.Millisecond}}
+
+	g, err := srv.client().SeekAll(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Err(); !errors.Is(err, context.DeadlineExceeded) || g.Matched() != nil {
+		t.Errorf("group ended with %v, matched %p", err, g.Matched())
+	}
+	if _, err := srv.client().FindAndStartAnyGame(ctx, opts...); err != ErrSeekTimeout {
+		t.Errorf("FindAndStartAnyGame returned %v, want ErrSeekTimeout", err)
+	}
+}
+
+// TestFindAndStartAnyGameExtra has two seeks matched at once, the second
+// game being aborted.
+func TestFindAndStartAnyGameExtra(t *testing.T) {
+	srv := newSeekServer(nil, `{"type":"gameStart","game":{"id":"g1"}}`, `{"type":"gameStart","game":{"id":"g2"}}`)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	close(done)
+	group := &SeekGroup{cancel: func() {}, done: done, matched: []*Seek{{}, {}}}
+	session, err := srv.client().findAndStart(ctx, 0, func(context.Context) (seeker, error) {
+		return group, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if session.Game.ID != "g1" {
+		t.Errorf("session follows %s, want g1", session.Game.ID)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.actions) != 1 || srv.actions[0] != "/api/board/game/g2/abort" {
+		t.Errorf("game actions %v, want g2 aborted", srv.actions)
+	}
+}
@@ -0,0 +1,495 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+/*
+ * GAME SESSION
+ */
+
+// GameSession follows a game of the authenticated user through its board
+// stream, keeping the position, move list and clocks up to date.
+type GameSession struct {
+	// Game is the game as announced by the gameStart event
+	Game Game
+	lichess *Lichess
+
+	mu sync.RWMutex
+	start *chess.Position
+	pos *chess.Position
+	moves []string
+	// clocks holds the remaining time of the mover after each move
+	clocks []time.Duration
+	clock Clock
+	whiteClock time.Duration
+	blackClock time.Duration
+	status string
+	winner string
+	createdAt time.Time
+	endedAt time.Time
+	ratingDiff *int
+	err error
+
+	updates chan BoardEvent
+	chat chan ChatLine
+	done chan struct{}
+	cancel context.CancelFunc
+}
+
+// Chat lines are buffered up to this many, further lines are dropped until
+// Chat is read.
+const sessionChatBuffer = 32
+
+// NewGameSession starts following game until it ends, ctx is done or the
+// session is closed. The board events are also sent on game.Board, and the
+// game is listed by ActiveGames meanwhile.
+func (l *Lichess) NewGameSession(ctx context.Context, game Game) *GameSession {
+	return l.newGameSession(ctx, game, false)
+}
+
+// newGameSession is NewGameSession, making the game the CurrentGame if
+// current is set.
+func (l *Lichess) newGameSession(ctx context.Context, game Game, current bool) *GameSession {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &GameSession{
+		Game: game,
+		lichess: l,
+		start: chess.NewPosition(),
+		pos: chess.NewPosition(),
+		updates: make(chan BoardEvent),
+		chat: make(chan ChatLine, sessionChatBuffer),
+		done: make(chan struct{}),
+		cancel: cancel,
+	}
+	s.Game.Board = s.updates
+	s.Game.Position = NewPositionTracker()
+	s.Game.lichess = l
+	l.registerGame(s.Game, current)
+
+	events := make(chan BoardEvent)
+	go func() {
+		err := l.WatchForBoardUpdates(ctx, game.ID, events)
+		// the error is set before events is closed, and done with it, so
+		// Err is final once Done is
+		s.mu.Lock()
+		if err != nil && err != context.Canceled {
+			s.err = err
+		}
+		s.mu.Unlock()
+		close(events)
+	}()
+	go func() {
+		defer close(s.done)
+		defer l.forgetGame(game.ID)
+		defer close(s.updates)
+		defer close(s.chat)
+		for event := range events {
+			if line, ok := event.(ChatLine); ok {
+				select {
+				case s.chat <- line:
+				default:
+					// chat is not worth holding up the game
+				}
+				continue
+			}
+			over := s.apply(&event)
+			s.Game.Position.Update(event)
+			select {
+			case s.updates <- event:
+			case <-ctx.Done():
+				over = true
+			}
+			if over {
+				// drain the stream goroutine so it can finish
+				cancel()
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// apply updates the session with event and reports whether the game is
+// over. Terminal positions are detected locally, event is then completed with
+// the status and winner Lichess is about to send.
+func (s *GameSession) apply(event *BoardEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state GameState
+	switch e := (*event).(type) {
+	case GameFull:
+		if pos, err := startPosition(string(e.Variant.Key), e.InitialFen); err == nil {
+			s.start = pos
+			if len(s.moves) == 0 {
+				s.pos = pos.Copy()
+			}
+		}
+		s.clock = e.Clock
+		if !e.CreatedAt.IsZero() {
+			s.createdAt = e.CreatedAt
+		}
+		state = e.State
+	case GameState:
+		s.winner = string(e.Winner)
+		state = e
+	default:
+		return false
+	}
+
+	s.playMoves(strings.Fields(state.Moves))
+	s.whiteClock, s.blackClock = state.Clocks()
+	s.status = string(state.Status)
+	for ply := len(s.clocks); ply < len(s.moves); ply++ {
+		if (ply%2 == 0) == (s.start.Turn() == chess.White) {
+			s.clocks = append(s.clocks, s.whiteClock)
+		} else {
+			s.clocks = append(s.clocks, s.blackClock)
+		}
+	}
+
+	if o := s.pos.Outcome(); isOngoing(s.status) && o.Status != "" {
+		s.status = o.Status
+		if !o.Draw {
+			s.winner = o.Winner.String()
+		}
+		state.Status, state.Winner = GameStatus(s.status), Color(s.winner)
+		if full, ok := (*event).(GameFull); ok {
+			full.State = state
+			*event = full
+		} else {
+			*event = state
+		}
+	}
+	if isOngoing(s.status) {
+		return false
+	}
+	if s.endedAt.IsZero() {
+		s.endedAt = time.Now()
+	}
+	return true
+}
+
+// startPosition returns the initial position of a game of variant, fen is
+// the initialFen Lichess sends, "startpos" for the usual one.
+func startPosition(variant string, fen string) (*chess.Position, error) {
+	v, err := chess.ParseVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+	if fen == "" || fen == "startpos" {
+		return chess.NewVariantPosition(v), nil
+	}
+	return chess.ParseVariantFEN(v, fen)
+}
+
+// playMoves brings the position to the move list sent by Lichess, replaying
+// from the start when moves were taken back.
+func (s *GameSession) playMoves(moves []string) {
+	prefix := len(s.moves) <= len(moves)
+	for i := 0; prefix && i < len(s.moves); i++ {
+		prefix = s.moves[i] == moves[i]
+	}
+	if !prefix {
+		s.pos = s.start.Copy()
+		s.moves = nil
+		s.clocks = nil
+	}
+	for _, uci := range moves[len(s.moves):] {
+		if _, err := s.pos.PlayUCI(uci); err != nil {
+			break
+		}
+		s.moves = append(s.moves, uci)
+	}
+}
+
+// Updates delivers the board events once they are applied. The session waits
+// for each event to be received, so the channel must be drained.
+func (s *GameSession) Updates() <-chan BoardEvent {
+	return s.updates
+}
+
+// Chat delivers the chat lines of the game, which are not sent on Updates.
+func (s *GameSession) Chat() <-chan ChatLine {
+	return s.chat
+}
+
+// Done is closed once the game is over, right away for terminal positions
+// detected locally, or when the board stream has ended.
+func (s *GameSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *GameSession) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// Close stops following the game and returns once the board stream is
+// closed, along with Updates and Chat.
+func (s *GameSession) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// Position returns a copy of the current position.
+func (s *GameSession) Position() *chess.Position {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pos.Copy()
+}
+
+func (s *GameSession) FEN() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pos.FEN()
+}
+
+// Moves returns the moves played so far in UCI notation.
+func (s *GameSession) Moves() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.moves...)
+}
+
+func (s *GameSession) LastMove() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.moves) == 0 {
+		return ""
+	}
+	return s.moves[len(s.moves)-1]
+}
+
+// TimeUsage analyses the time spent on the moves played so far. Games started
+// with black to move get an empty first ply, so even plies stay white's.
+func (s *GameSession) TimeUsage() TimeUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	clocks := s.clocks
+	if s.start.Turn() == chess.Black {
+		clocks = append([]time.Duration{s.clock.InitialTime()}, clocks...)
+	}
+	return AnalyzeTime(s.clock.InitialTime(), s.clock.IncrementTime(), clocks)
+}
+
+// Clock returns the remaining time of color ("white" or "black") as of the
+// last event.
+func (s *GameSession) Clock(color string) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if color == "black" {
+		return s.blackClock
+	}
+	return s.whiteClock
+}
+
+// Status returns the game status name, e.g. "started" or "mate".
+func (s *GameSession) Status() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+func (s *GameSession) Winner() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.winner
+}
+
+// GameResult is the outcome of a finished game, from the point of view of the
+// authenticated user.
+type GameResult struct {
+	GameID string
+	Color string
+	// Winner is "white", "black" or empty for a draw
+	Winner string
+	Status string
+	Moves []string
+	Duration time.Duration
+	// RatingDiff is only known once the gameFinish event was passed to
+	// GameSession.Finish, and for rated games
+	RatingDiff *int
+}
+
+func (r GameResult) Won() bool {
+	return r.Winner != "" && r.Winner == r.Color
+}
+
+func (r GameResult) Lost() bool {
+	return r.Winner != "" && r.Winner != r.Color
+}
+
+func (r GameResult) Drawn() bool {
+	return r.Winner == ""
+}
+
+// Finish records the gameFinish event of the game from the event stream,
+// which carries the rating change.
+func (s *GameSession) Finish(game Game) {
+	if game.ID != s.Game.ID {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratingDiff = game.RatingDiff
+	if game.Status != nil && isOngoing(s.status) {
+		s.status = string(game.Status.Name)
+		s.winner = string(game.Winner)
+		s.endedAt = time.Now()
+	}
+}
+
+// Result returns the outcome of the game, ok is false while it is ongoing.
+func (s *GameSession) Result() (result GameResult, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if isOngoing(s.status) {
+		return GameResult{}, false
+	}
+	result = GameResult{
+		GameID: s.Game.ID,
+		Color: string(s.Game.Color),
+		Winner: s.winner,
+		Status: s.status,
+		Moves: append([]string(nil), s.moves...),
+		RatingDiff: s.ratingDiff,
+	}
+	if !s.createdAt.IsZero() {
+		result.Duration = s.endedAt.Sub(s.createdAt)
+	}
+	return result, true
+}
+
+// IsOver reports whether the game has ended.
+func (s *GameSession) IsOver() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !isOngoing(s.status)
+}
+
+// IsMyTurn reports whether the authenticated user is to move.
+func (s *GameSession) IsMyTurn() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pos.Turn().String() == string(s.Game.Color)
+}
+
+// Submitting a move is retried this many times on network failures, each
+// attempt being bounded by moveTimeout. moveConfirmWait is how long the
+// board stream is given to report a move whose submission failed.
+const moveAttempts = 3
+
+var (
+	moveTimeout = 10 * time.Second
+	moveConfirmWait = 2 * time.Second
+)
+
+// Move plays uci on the board. If a submission fails in a way that leaves
+// its outcome unknown, the session first waits for the board stream to tell
+// whether the move landed before trying again, so a move is never sent twice.
+// A retry being rejected, e.g. as the opponent is now to move, may also mean
+// an earlier attempt landed unseen: Lichess is then asked for the game before
+// the rejection is returned.
+func (s *GameSession) Move(ctx context.Context, uci string) error {
+	s.mu.RLock()
+	ply := len(s.moves)
+	s.mu.RUnlock()
+
+	var err error
+	for attempt := 0; attempt < moveAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, moveTimeout)
+		err = s.lichess.makeMove(attemptCtx, s.Game.ID, uci, false)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		// a rejected move did not land, only a failure in transit or on
+		// the server leaves the outcome unknown
+		if !retryableMove(err) {
+			if attempt > 0 && s.landedEarlier(ctx, ply, uci) {
+				return nil
+			}
+			return err
+		}
+		if s.landed(ctx, ply, uci) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// landed waits a moment for the stream to report a move at ply, and reports
+// whether that move is uci.
+func (s *GameSession) landed(ctx context.Context, ply int, uci string) bool {
+	deadline := time.Now().Add(moveConfirmWait)
+	for {
+		s.mu.RLock()
+		played := ""
+		if len(s.moves) > ply {
+			played = s.moves[ply]
+		}
+		s.mu.RUnlock()
+		if played != "" {
+			return played == uci
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return false
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+// landedEarlier reports whether uci was played at ply by an attempt whose
+// outcome was unknown, from the board stream or else from the ongoing games
+// of the user.
+func (s *GameSession) landedEarlier(ctx context.Context, ply int, uci string) bool {
+	if s.landed(ctx, ply, uci) {
+		return true
+	}
+	s.mu.RLock()
+	seen := len(s.moves)
+	s.mu.RUnlock()
+	if seen > ply {
+		// the stream reported another move
+		return false
+	}
+	games, err := s.lichess.GetOngoingGames(ctx, 50)
+	if err != nil {
+		return false
+	}
+	for _, g := range games {
+		if g.GameID == s.Game.ID {
+			return g.LastMove == uci && !g.IsMyTurn
+		}
+	}
+	return false
+}
+
+// retryableMove reports whether a failed move submission may not have
+// reached Lichess, as opposed to being rejected.
+func retryableMove(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, ErrServiceUnavailable)
+}
@@ -0,0 +1,72 @@
+package lichess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMoveRetryRejectedAfterLanding has the first submission time out once
+// Lichess played it, the retry being refused as it is no longer the user's
+// turn, while the board stream lags behind.
+func TestMoveRetryRejectedAfterLanding(t *testing.T) {
+	defer func(timeout, wait time.Duration) {
+		moveTimeout, moveConfirmWait = timeout, wait
+	}(moveTimeout, moveConfirmWait)
+	moveTimeout, moveConfirmWait = 50*time.Millisecond, 50*time.Millisecond
+
+	for _, landed := range []bool{true, false} {
+		var posts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/api/board/game/stream/"):
+				w.Write([]byte(`{"type":"gameFull","id":"g1","variant":{"key":"standard"},"state":{"type":"gameState","moves":"","status":"started"}}` + "\n"))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			case strings.HasPrefix(r.URL.Path, "/api/board/game/g1/move/"):
+				if posts.Add(1) == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"Not your turn, or game already over"}`))
+			case r.URL.Path == nowPlayingPath:
+				lastMove := ""
+				if landed {
+					lastMove = "e2e4"
+				}
+				fmt.Fprintf(w, `{"nowPlaying":[{"gameId":"g1","lastMove":%q,"isMyTurn":%t}]}`, lastMove, !landed)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+
+		l := NewLichess(WithBaseURL(srv.URL))
+		l.SetClient(NewClientWithToken("token"))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		s := l.NewGameSession(ctx, Game{ID: "g1", Color: ColorWhite})
+		go func() {
+			for range s.Updates() {
+			}
+		}()
+		err := s.Move(ctx, "e2e4")
+		var rejected *MoveRejectedError
+		switch {
+		case landed && err != nil:
+			t.Errorf("move that landed reported as %v", err)
+		case !landed && !errors.As(err, &rejected):
+			t.Errorf("rejected move reported as %v", err)
+		}
+		if n := posts.Load(); n != 2 {
+			t.Errorf("move posted %d times, want 2", n)
+		}
+		s.Close()
+		cancel()
+		srv.Close()
+	}
+}
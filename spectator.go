@@ -0,0 +1,217 @@
+package lichess
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+/*
+ * SPECTATOR
+ */
+
+const spectatorUpdateBuffer = 64
+
+// SpectatorSession follows any public game through its move stream and keeps
+// track of the current position and clocks. It is read-only, the
+// authenticated user does not need to be playing.
+type SpectatorSession struct {
+	GameID string
+	lichess *Lichess
+
+	mu sync.RWMutex
+	info GameMoveEvent
+	pos *chess.Position
+	lastMove string
+	whiteClock time.Duration
+	blackClock time.Duration
+	status string
+	winner string
+	err error
+
+	updates chan GameMoveEvent
+	done chan struct{}
+	cancel context.CancelFunc
+}
+
+// Spectate starts following gameID until the game ends, ctx is done or the
+// session is closed.
+func (l *Lichess) Spectate(ctx context.Context, gameID string) *SpectatorSession {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &SpectatorSession{
+		GameID: gameID,
+		lichess: l,
+		pos: chess.NewPosition(),
+		updates: make(chan GameMoveEvent, spectatorUpdateBuffer),
+		done: make(chan struct{}),
+		cancel: cancel,
+	}
+
+	events := make(chan GameMoveEvent)
+	go func() {
+		err := l.StreamGameMoves(ctx, gameID, events)
+		close(events)
+		s.mu.Lock()
+		if err != nil && err != context.Canceled {
+			s.err = err
+		}
+		s.mu.Unlock()
+	}()
+	go func() {
+		defer close(s.done)
+		defer close(s.updates)
+		for event := range events {
+			s.apply(event)
+			select {
+			case s.updates <- event:
+			default:
+				// the consumer is lagging behind, the state stays accurate
+			}
+		}
+	}()
+	return s
+}
+
+func (s *SpectatorSession) apply(event GameMoveEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !event.IsMove() {
+		s.info = event
+	}
+	if event.FEN != "" {
+		if err := s.advance(event); err != nil {
+			s.lichess.log().Warn("lichess: cannot follow the spectated position", "game", s.GameID, "fen", event.FEN, "error", err)
+		}
+	}
+	if event.LastMove != "" {
+		s.lastMove = event.LastMove
+	}
+	if event.WhiteClock != 0 || event.BlackClock != 0 {
+		s.whiteClock, s.blackClock = event.Clocks()
+	}
+	if event.Status != nil {
+		s.status = string(event.Status.Name)
+	}
+	if event.Winner != "" {
+		s.winner = event.Winner
+	}
+}
+
+// advance moves the position to the fen of event. The description carries a
+// full FEN, while moves only carry the board: the last move is then played on
+// the position, which keeps the castling rights and en passant square, and
+// the board alone is taken with the side to move if that does not match,
+// e.g. after a reconnection.
+func (s *SpectatorSession) advance(event GameMoveEvent) error {
+	v, _ := chess.ParseVariant(string(s.info.Variant.Key))
+	fields := strings.Fields(event.FEN)
+	if len(fields) >= 4 {
+		pos, err := chess.ParseVariantFEN(v, event.FEN)
+		if err == nil {
+			s.pos = pos
+		}
+		return err
+	}
+
+	board := fields[0]
+	next := s.pos.Copy()
+	if event.LastMove != "" {
+		if _, err := next.PlayUCI(event.LastMove); err == nil && strings.Fields(next.FEN())[0] == board {
+			s.pos = next
+			return nil
+		}
+	}
+	// the side to move is the opponent of the piece that just moved
+	turn := s.pos.Turn().Other()
+	if m, err := chess.ParseUCI(event.LastMove); err == nil {
+		if played, err := chess.ParseVariantFEN(v, board+" w - -"); err == nil && played.PieceAt(m.To) != chess.NoPiece {
+			turn = played.PieceAt(m.To).Color.Other()
+		}
+	}
+	pos, err := chess.ParseVariantFEN(v, board+" "+turn.String()[:1]+" - -")
+	if err != nil {
+		return err
+	}
+	s.pos = pos
+	return nil
+}
+
+// Updates delivers the stream messages as they are applied. Messages are
+// dropped rather than blocking the session when the channel is not drained.
+func (s *SpectatorSession) Updates() <-chan GameMoveEvent {
+	return s.updates
+}
+
+// Done is closed when the game stream has ended.
+func (s *SpectatorSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *SpectatorSession) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// Close stops following the game and returns once the move stream is
+// closed, along with Updates.
+func (s *SpectatorSession) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// Info returns the game description sent at the start of the stream.
+func (s *SpectatorSession) Info() GameMoveEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.info
+}
+
+// Position returns a copy of the current position.
+func (s *SpectatorSession) Position() *chess.Position {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pos.Copy()
+}
+
+func (s *SpectatorSession) FEN() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pos.FEN()
+}
+
+// LastMove returns the last move played in UCI notation.
+func (s *SpectatorSession) LastMove() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastMove
+}
+
+// Clock returns the remaining time of color ("white" or "black") as of the
+// last move.
+func (s *SpectatorSession) Clock(color string) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if color == "black" {
+		return s.blackClock
+	}
+	return s.whiteClock
+}
+
+// Status returns the game status name, e.g. "started" or "mate".
+func (s *SpectatorSession) Status() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+func (s *SpectatorSession) Winner() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.winner
+}
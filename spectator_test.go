@@ -0,0 +1,47 @@
+package lichess
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hmccarty/lichess/chess"
+)
+
+func TestSpectatorPosition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// move messages only carry the board of the position
+		w.Write([]byte(`{"id":"g1","variant":{"key":"standard"},"fen":"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1","turns":0,"status":{"name":"started"}}
+{"fen":"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR","lm":"e2e4","wc":180,"bc":180}
+{"fen":"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR","lm":"e7e5","wc":179,"bc":178}
+{"fen":"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPPKPPP/RNBQ1BNR","lm":"e1e2","wc":177,"bc":178}
+`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s := NewLichess(WithBaseURL(srv.URL)).Spectate(ctx, "g1")
+	<-s.Done()
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fen, want := s.FEN(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPPKPPP/RNBQ1BNR b kq - 1 2"; fen != want {
+		t.Errorf("FEN() = %q, want %q", fen, want)
+	}
+	if s.LastMove() != "e1e2" || s.Clock("white") != 177*time.Second {
+		t.Errorf("last move %q, white clock %v", s.LastMove(), s.Clock("white"))
+	}
+}
+
+// TestSpectatorResync has a move missing from the stream, the board being
+// taken as is with the side to move.
+func TestSpectatorResync(t *testing.T) {
+	s := &SpectatorSession{lichess: NewLichess(), pos: chess.NewPosition()}
+	s.apply(GameMoveEvent{FEN: "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR", LastMove: "e7e5"})
+	if fen, want := s.FEN(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w - - 0 1"; fen != want {
+		t.Errorf("FEN() = %q, want %q", fen, want)
+	}
+}
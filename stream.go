@@ -0,0 +1,318 @@
+package lichess
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+/*
+ * STREAMS
+ */
+
+// streamSpec describes how runStream follows an NDJSON endpoint.
+type streamSpec[T any] struct {
+	path string
+	// body is POSTed to path when set, e.g. a list of IDs
+	body *string
+	// resume returns the path to reconnect to, path is reused if nil
+	resume func() string
+	// reconnect makes dropped connections be reestablished until finished
+	// reports true
+	reconnect bool
+	finished func() bool
+	// handle is called on every value before it is delivered, and reports
+	// whether to deliver it
+	handle func(v *T) bool
+	// follow optionally derives from a delivered value another one, which
+	// is delivered right after it
+	follow func(v T) (T, bool)
+	// decode replaces the JSON decoding of each line, e.g. to pick the
+	// concrete type of an interface
+	decode func(line []byte) (T, error)
+	// connection is told when a reconnecting stream is dropped and reopened
+	connection func(ConnectionEvent)
+}
+
+// Lichess sends a keep-alive line every few seconds on idle streams.
+const defaultStreamIdleTimeout = time.Minute
+
+// SetStreamIdleTimeout sets how long a stream may go without receiving
+// anything, keep-alive lines included, before it is considered stalled: it
+// is then reconnected, or ended with ErrStreamStalled if it does not
+// reconnect. Zero selects the default of a minute, a negative timeout waits
+// forever.
+func (l *Lichess) SetStreamIdleTimeout(timeout time.Duration) {
+	l.streamIdleTimeout = timeout
+}
+
+func WithStreamIdleTimeout(timeout time.Duration) ClientOption {
+	return func(l *Lichess) {
+		l.streamIdleTimeout = timeout
+	}
+}
+
+func (l *Lichess) idleTimeout() time.Duration {
+	if l.streamIdleTimeout == 0 {
+		return defaultStreamIdleTimeout
+	}
+	return l.streamIdleTimeout
+}
+
+// idleBody closes a stream body once nothing was read from it for timeout,
+// which unblocks the pending Read with ErrStreamStalled.
+type idleBody struct {
+	body io.ReadCloser
+	timeout time.Duration
+	timer *time.Timer
+	stalled atomic.Bool
+}
+
+func newIdleBody(body io.ReadCloser, timeout time.Duration) *idleBody {
+	b := &idleBody{body: body, timeout: timeout}
+	if timeout >= 0 {
+		b.timer = time.AfterFunc(timeout, func() {
+			b.stalled.Store(true)
+			body.Close()
+		})
+	}
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err != nil && b.stalled.Load() {
+		return n, ErrStreamStalled
+	}
+	if n > 0 {
+		b.resume()
+	}
+	return n, err
+}
+
+// pause stops the timeout while the values read are waiting for the
+// consumer, a slow consumer saying nothing about the connection.
+func (b *idleBody) pause() {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+func (b *idleBody) resume() {
+	if b.timer != nil {
+		b.timer.Reset(b.timeout)
+	}
+}
+
+func (b *idleBody) Close() error {
+	b.pause()
+	return b.body.Close()
+}
+
+const (
+	// Disconnected is sent when the connection of a stream is lost and
+	// about to be reopened
+	Disconnected = "disconnected"
+	// Reconnected is sent once it is reopened
+	Reconnected = "reconnected"
+)
+
+// ConnectionEvent reports a change of the connection of a stream.
+type ConnectionEvent struct {
+	Type string
+	// Err is what broke the connection, for Disconnected
+	Err error
+	// Delay is the wait before reconnecting, for Disconnected
+	Delay time.Duration
+}
+
+// runStream decodes the values of an NDJSON stream and sends them to ch until
+// the stream ends, ctx is done or an error occurs. Keep-alive lines are
+// skipped, and count as activity for the idle timeout. With reconnect, broken
+// or stalled connections are reopened after the reconnectDelays, while
+// errors reported by Lichess end the stream.
+func runStream[T any](ctx context.Context, l *Lichess, spec streamSpec[T], ch chan<- T) error {
+	path := spec.path
+	dropped := false
+	for attempt := 0; ; attempt++ {
+		resp, err := l.openStream(ctx, path, spec.body)
+		if err != nil {
+			return err
+		}
+		if dropped && spec.connection != nil {
+			spec.connection(ConnectionEvent{Type: Reconnected})
+		}
+		body := newIdleBody(resp.Body, l.idleTimeout())
+
+		dec := newNDJSONReader(body, l.lineLimit())
+		for {
+			var v T
+			var line json.RawMessage
+			switch {
+			case spec.decode != nil:
+				if err = dec.Decode(&line); err == nil {
+					v, err = spec.decode(line)
+				}
+				if err == nil {
+					// the type names the kind of event
+					l.checkLine(line, any(v), "type")
+				}
+			case l.strict:
+				if err = dec.Decode(&line); err == nil {
+					err = l.unmarshalLine(line, &v)
+				}
+			default:
+				err = dec.Decode(&v)
+			}
+			if err != nil {
+				break
+			}
+			attempt = 0
+			if spec.handle != nil && !spec.handle(&v) {
+				continue
+			}
+			values := []T{v}
+			if spec.follow != nil {
+				if next, ok := spec.follow(v); ok {
+					values = append(values, next)
+				}
+			}
+			body.pause()
+			for _, v := range values {
+				select {
+				case ch <- v:
+				case <-ctx.Done():
+					body.Close()
+					return ctx.Err()
+				}
+			}
+			body.resume()
+		}
+		body.Close()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !spec.reconnect {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err == io.EOF && spec.finished != nil && spec.finished() {
+			return nil
+		}
+		switch err.(type) {
+		case *ResponseTooLargeError, *StreamError:
+			return err
+		}
+
+		delay := reconnectDelays[len(reconnectDelays)-1]
+		if attempt < len(reconnectDelays) {
+			delay = reconnectDelays[attempt]
+		}
+		l.log().Debug("lichess: stream dropped, reconnecting", "path", path, "delay", delay, "error", err)
+		l.hooks.streamReconnect(path, attempt, delay, err)
+		if spec.connection != nil {
+			spec.connection(ConnectionEvent{Type: Disconnected, Err: err, Delay: delay})
+		}
+		dropped = true
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+		if spec.resume != nil {
+			path = spec.resume()
+		}
+	}
+}
+
+// Stream delivers the values of a streaming endpoint on a channel, the error
+// that ended the stream, if any, is sent on Errors.
+type Stream[T any] struct {
+	events chan T
+	errs chan error
+	connection chan ConnectionEvent
+	cancel context.CancelFunc
+	// done is closed once the goroutine reading the stream has returned
+	done chan struct{}
+}
+
+// connectionBuffer is the number of connection events kept for a reader
+// that does not keep up, later ones are dropped.
+const connectionBuffer = 16
+
+func newStream[T any](ctx context.Context, l *Lichess, spec streamSpec[T]) *Stream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Stream[T]{
+		events: make(chan T),
+		errs: make(chan error, 1),
+		connection: make(chan ConnectionEvent, connectionBuffer),
+		cancel: cancel,
+		done: make(chan struct{}),
+	}
+	spec.connection = func(e ConnectionEvent) {
+		select {
+		case s.connection <- e:
+		default:
+		}
+	}
+	go func() {
+		defer close(s.done)
+		err := runStream(ctx, l, spec, s.events)
+		close(s.events)
+		close(s.connection)
+		if err != nil && err != context.Canceled {
+			s.errs <- err
+		}
+		close(s.errs)
+	}()
+	return s
+}
+
+// failedStream returns a stream that ends right away with err.
+func failedStream[T any](err error) *Stream[T] {
+	s := &Stream[T]{
+		events: make(chan T),
+		errs: make(chan error, 1),
+		connection: make(chan ConnectionEvent),
+		cancel: func() {},
+		done: make(chan struct{}),
+	}
+	close(s.events)
+	close(s.connection)
+	s.errs <- err
+	close(s.errs)
+	close(s.done)
+	return s
+}
+
+// Events is closed once the stream has ended.
+func (s *Stream[T]) Events() <-chan T {
+	return s.events
+}
+
+// Connection receives the Disconnected and Reconnected events of streams
+// that reconnect, it is closed with Events. Reading it is optional.
+func (s *Stream[T]) Connection() <-chan ConnectionEvent {
+	return s.connection
+}
+
+// Errors receives at most one error and is closed after Events.
+func (s *Stream[T]) Errors() <-chan error {
+	return s.errs
+}
+
+// Done is closed once the stream has ended and its connection is released.
+func (s *Stream[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the stream and returns once its connection is closed, along
+// with Events and Connection. Events need not be drained, the values not
+// received are dropped.
+func (s *Stream[T]) Close() {
+	s.cancel()
+	<-s.done
+}
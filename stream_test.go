@@ -0,0 +1,40 @@
+package lichess
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamSlowConsumerNotStalled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			w.Write([]byte(`{"type":"ping"}` + "\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	l := NewLichess(WithBaseURL(srv.URL), WithStreamIdleTimeout(100*time.Millisecond))
+
+	ch := make(chan Event)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- runStream(context.Background(), l, streamSpec[Event]{path: "/stream"}, ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		// the consumer takes longer than the idle timeout on every event
+		time.Sleep(250 * time.Millisecond)
+		n++
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("stream ended with %v after %d events", err, n)
+	}
+	if n != 3 {
+		t.Errorf("got %d events, want 3", n)
+	}
+}
@@ -0,0 +1,110 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+/*
+ * STUDIES
+ */
+
+// GET
+const studyChapterPGNPath = "/api/study/%s/%s.pgn" // StudyID, ChapterID
+const studyPGNPath = "/api/study/%s.pgn" // StudyID
+const userStudiesPGNPath = "/study/by/%s/export.pgn" // Username
+const userStudiesPath = "/api/study/by/%s" // Username
+
+// StudyExportOptions are the settings of the study exports. The zero value
+// exports everything, like Lichess does.
+type StudyExportOptions struct {
+	NoClocks bool
+	NoComments bool
+	NoVariations bool
+	// Orientation adds an Orientation tag to each chapter
+	Orientation bool
+}
+
+func (o StudyExportOptions) values() url.Values {
+	v := url.Values{}
+	if o.NoClocks {
+		v.Set("clocks", "false")
+	}
+	if o.NoComments {
+		v.Set("comments", "false")
+	}
+	if o.NoVariations {
+		v.Set("variations", "false")
+	}
+	if o.Orientation {
+		v.Set("orientation", "true")
+	}
+	return v
+}
+
+// ExportStudyChapter returns the PGN of a chapter. Private studies are only
+// exported for their owner and members, with the study:read scope.
+func (l *Lichess) ExportStudyChapter(ctx context.Context, studyID string, chapterID string, opts StudyExportOptions) (string, error) {
+	path := withQuery(fmt.Sprintf(studyChapterPGNPath, url.PathEscape(studyID), url.PathEscape(chapterID)), opts.values())
+	return l.getText(ctx, path, string(FormatPGN))
+}
+
+// ExportStudy returns the PGN of every chapter of a study.
+func (l *Lichess) ExportStudy(ctx context.Context, studyID string, opts StudyExportOptions) (string, error) {
+	path := withQuery(fmt.Sprintf(studyPGNPath, url.PathEscape(studyID)), opts.values())
+	return l.getText(ctx, path, string(FormatPGN))
+}
+
+// ExportAllStudiesOf copies the chapters of all the studies of username into
+// w, which can be a lot of PGN. Private studies are only included for the
+// authenticated user's own studies.
+func (l *Lichess) ExportAllStudiesOf(ctx context.Context, username string, opts StudyExportOptions, w io.Writer) (int64, error) {
+	path := withQuery(fmt.Sprintf(userStudiesPGNPath, url.PathEscape(username)), opts.values())
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", string(FormatPGN))
+	return l.copyTo(req, w)
+}
+
+// StudyMetadata describes a study without its chapters. The dates are in
+// milliseconds since the epoch.
+type StudyMetadata struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	CreatedAt int64 `json:"createdAt"`
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+func (m StudyMetadata) UpdateTime() time.Time {
+	return time.UnixMilli(m.UpdatedAt)
+}
+
+// StreamStudiesOf streams the studies of username, without their content.
+// Private studies are only included for the authenticated user's own
+// studies.
+func (l *Lichess) StreamStudiesOf(ctx context.Context, username string) *Stream[StudyMetadata] {
+	return newStream(ctx, l, streamSpec[StudyMetadata]{path: fmt.Sprintf(userStudiesPath, url.PathEscape(username))})
+}
+
+// StudyLastModified returns when a study was last changed, without
+// downloading it, so local copies only need to be refreshed when outdated.
+func (l *Lichess) StudyLastModified(ctx context.Context, studyID string) (time.Time, error) {
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	req, err := l.newRequest(ctx, http.MethodHead, fmt.Sprintf(studyPGNPath, url.PathEscape(studyID)), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := l.do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp.Body.Close()
+	return http.ParseTime(resp.Header.Get("Last-Modified"))
+}
@@ -0,0 +1,100 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+/*
+ * GAME SUMMARY
+ */
+
+// GameSummary is the final record of a game, as exported once it is over.
+type GameSummary struct {
+	ID string `json:"id"`
+	Rated bool `json:"rated"`
+	Variant string `json:"variant"`
+	Speed string `json:"speed"`
+	Perf string `json:"perf"`
+	CreatedAt int64 `json:"createdAt"`
+	LastMoveAt int64 `json:"lastMoveAt"`
+	Status string `json:"status"`
+	Winner string `json:"winner,omitempty"`
+	Players Players `json:"players"`
+	Opening *Opening `json:"opening,omitempty"`
+	Moves string `json:"moves"`
+	PGN string `json:"pgn,omitempty"`
+	Clock *SummaryClock `json:"clock,omitempty"`
+	// Clocks holds the remaining time after each move in centiseconds
+	Clocks []int64 `json:"clocks,omitempty"`
+}
+
+type Opening struct {
+	ECO string `json:"eco"`
+	Name string `json:"name"`
+	Ply int `json:"ply"`
+}
+
+// SummaryClock is the time control of an exported game, in seconds.
+type SummaryClock struct {
+	Initial int `json:"initial"`
+	Increment int `json:"increment"`
+	TotalTime int `json:"totalTime"`
+}
+
+// Result returns the PGN result token, "1-0", "0-1", "1/2-1/2" or "*".
+func (s GameSummary) Result() string {
+	return pgnResult(s.Status, s.Winner)
+}
+
+// RatingDiff returns the rating change of color ("white" or "black").
+func (s GameSummary) RatingDiff(color string) int {
+	if color == "black" {
+		return s.Players.Black.RatingDiff
+	}
+	return s.Players.White.RatingDiff
+}
+
+// TimeUsage analyses the clock times of the game, which are only exported
+// for real-time games.
+func (s GameSummary) TimeUsage() TimeUsage {
+	if s.Clock == nil {
+		return TimeUsage{}
+	}
+	clocks := make([]time.Duration, len(s.Clocks))
+	for i, cs := range s.Clocks {
+		clocks[i] = Centis(cs)
+	}
+	initial := time.Duration(s.Clock.Initial) * time.Second
+	increment := time.Duration(s.Clock.Increment) * time.Second
+	return AnalyzeTime(initial, increment, clocks)
+}
+
+// GetGameSummary exports a finished game with its opening and full PGN.
+func (l *Lichess) GetGameSummary(ctx context.Context, gameID string) (GameSummary, error) {
+	opts := ExportOptions{Opening: true, Clocks: true, PGNInJSON: true}
+	path := withQuery(fmt.Sprintf(exportGamePath, url.PathEscape(gameID)), opts.values())
+
+	summary := GameSummary{}
+	if err := l.getJSON(ctx, path, &summary); err != nil {
+		return GameSummary{}, err
+	}
+	return summary, nil
+}
+
+// OnGameEnd makes StreamEvents fetch the final game once a gameFinish event
+// arrives and pass it to fn, from its own goroutine. Games that cannot be
+// exported are skipped. A nil fn disables the lookup.
+func (l *Lichess) OnGameEnd(fn func(GameSummary)) {
+	l.onGameEnd = fn
+}
+
+func (l *Lichess) summarizeGame(ctx context.Context, gameID string) {
+	summary, err := l.GetGameSummary(ctx, gameID)
+	if err != nil {
+		return
+	}
+	l.onGameEnd(summary)
+}
@@ -0,0 +1,213 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * SWISS TOURNAMENTS
+ */
+
+// GET
+const swissResultsPath = "/api/swiss/%s/results" // SwissID
+const swissTRFPath = "/swiss/%s.trf" // SwissID
+const swissGamesPath = "/api/swiss/%s/games" // SwissID
+
+// POST
+const createSwissPath = "/api/swiss/new/%s" // TeamID
+const joinSwissPath = "/api/swiss/%s/join" // SwissID
+const withdrawSwissPath = "/api/swiss/%s/withdraw" // SwissID
+const scheduleSwissRoundPath = "/api/swiss/%s/schedule-next-round" // SwissID
+
+type Swiss struct {
+	ID string `json:"id"`
+	CreatedBy string `json:"createdBy"`
+	StartsAt time.Time `json:"startsAt"`
+	Name string `json:"name"`
+	Clock ArenaClock `json:"clock"`
+	Variant string `json:"variant"`
+	Round int `json:"round"`
+	NbRounds int `json:"nbRounds"`
+	NbPlayers int `json:"nbPlayers"`
+	NbOngoing int `json:"nbOngoing"`
+	// Status is "created", "started" or "finished"
+	Status string `json:"status"`
+	Rated bool `json:"rated"`
+	NextRound *SwissNextRound `json:"nextRound,omitempty"`
+}
+
+type SwissNextRound struct {
+	At time.Time `json:"at"`
+	// In is the number of seconds before the round starts
+	In int `json:"in"`
+}
+
+// SwissOptions describes a Swiss tournament to create. Clock and the number
+// of rounds are required.
+type SwissOptions struct {
+	Name string
+	Clock ArenaClock
+	NbRounds int
+	StartsAt time.Time
+	// RoundInterval is the break between rounds, Lichess picks one from
+	// the clock if zero
+	RoundInterval time.Duration
+	Variant string
+	Position string
+	Casual bool
+	Description string
+	Password string
+	// ForbiddenPairings lists the pairs of usernames never to pair
+	// together
+	ForbiddenPairings [][2]string
+	Conditions ArenaConditions
+}
+
+func (o SwissOptions) values() url.Values {
+	v := url.Values{}
+	if o.Name != "" {
+		v.Set("name", o.Name)
+	}
+	v.Set("clock.limit", strconv.Itoa(o.Clock.Limit))
+	v.Set("clock.increment", strconv.Itoa(o.Clock.Increment))
+	v.Set("nbRounds", strconv.Itoa(o.NbRounds))
+	if !o.StartsAt.IsZero() {
+		v.Set("startsAt", strconv.FormatInt(o.StartsAt.UnixMilli(), 10))
+	}
+	if o.RoundInterval > 0 {
+		v.Set("roundInterval", strconv.Itoa(int(o.RoundInterval.Seconds())))
+	}
+	if o.Variant != "" {
+		v.Set("variant", o.Variant)
+	}
+	if o.Position != "" {
+		v.Set("position", o.Position)
+	}
+	v.Set("rated", strconv.FormatBool(!o.Casual))
+	if o.Description != "" {
+		v.Set("description", o.Description)
+	}
+	if o.Password != "" {
+		v.Set("password", o.Password)
+	}
+	if len(o.ForbiddenPairings) > 0 {
+		pairs := make([]string, len(o.ForbiddenPairings))
+		for i, p := range o.ForbiddenPairings {
+			pairs[i] = p[0] + " " + p[1]
+		}
+		v.Set("forbiddenPairings", strings.Join(pairs, "\n"))
+	}
+	c := o.Conditions
+	if c.MinRating > 0 {
+		v.Set("conditions.minRating.rating", strconv.Itoa(c.MinRating))
+	}
+	if c.MaxRating > 0 {
+		v.Set("conditions.maxRating.rating", strconv.Itoa(c.MaxRating))
+	}
+	if c.MinRatedGames > 0 {
+		v.Set("conditions.nbRatedGame.nb", strconv.Itoa(c.MinRatedGames))
+	}
+	return v
+}
+
+// CreateSwiss creates a Swiss tournament for the members of a team, which
+// the authenticated user must lead.
+func (l *Lichess) CreateSwiss(ctx context.Context, teamID string, opts SwissOptions) (Swiss, error) {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return Swiss{}, err
+	}
+	swiss := Swiss{}
+	err := l.postFormJSON(ctx, fmt.Sprintf(createSwissPath, url.PathEscape(teamID)), opts.values(), &swiss)
+	return swiss, err
+}
+
+// JoinSwiss enters the authenticated user in a Swiss tournament, password is
+// only needed for private ones.
+func (l *Lichess) JoinSwiss(ctx context.Context, id string, password string) error {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return err
+	}
+	v := url.Values{}
+	if password != "" {
+		v.Set("password", password)
+	}
+	return l.postForm(ctx, fmt.Sprintf(joinSwissPath, url.PathEscape(id)), v)
+}
+
+// WithdrawSwiss leaves a Swiss tournament, or skips the following rounds of
+// one that has started.
+func (l *Lichess) WithdrawSwiss(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(withdrawSwissPath, url.PathEscape(id)), nil)
+}
+
+// ScheduleSwissRound sets the start of the next round of a Swiss tournament
+// created by the authenticated user.
+func (l *Lichess) ScheduleSwissRound(ctx context.Context, id string, at time.Time) error {
+	if err := l.authorized().requireScope(ScopeTournamentWrite); err != nil {
+		return err
+	}
+	v := url.Values{"date": {strconv.FormatInt(at.UnixMilli(), 10)}}
+	return l.postForm(ctx, fmt.Sprintf(scheduleSwissRoundPath, url.PathEscape(id)), v)
+}
+
+// SwissResult is the standing of a player in a Swiss tournament.
+type SwissResult struct {
+	Rank int `json:"rank"`
+	Points float64 `json:"points"`
+	TieBreak float64 `json:"tieBreak"`
+	Rating int `json:"rating"`
+	Username string `json:"username"`
+	Title string `json:"title,omitempty"`
+	Performance int `json:"performance,omitempty"`
+	// Absent is set on the players who withdrew
+	Absent bool `json:"absent,omitempty"`
+}
+
+// StreamSwissResults streams the standings of a Swiss tournament, best
+// first, up to nb players or all of them if nb is zero.
+func (l *Lichess) StreamSwissResults(ctx context.Context, id string, nb int) *Stream[SwissResult] {
+	path := fmt.Sprintf(swissResultsPath, url.PathEscape(id))
+	if nb > 0 {
+		path += "?nb=" + strconv.Itoa(nb)
+	}
+	return newStream(ctx, l, streamSpec[SwissResult]{path: path})
+}
+
+// ExportSwissTRF writes a Swiss tournament to w in the Tournament Report
+// File format used by FIDE.
+func (l *Lichess) ExportSwissTRF(ctx context.Context, id string, w io.Writer) (int64, error) {
+	req, err := l.newRequest(ctx, http.MethodGet, fmt.Sprintf(swissTRFPath, url.PathEscape(id)), nil)
+	if err != nil {
+		return 0, err
+	}
+	return l.copyTo(req, w)
+}
+
+// StreamSwissGames streams the games of a Swiss tournament as they are
+// exported, set opts.PGNInJSON to get the PGN of each game along with it.
+func (l *Lichess) StreamSwissGames(ctx context.Context, id string, opts ExportOptions) *Stream[GameSummary] {
+	path := withQuery(fmt.Sprintf(swissGamesPath, url.PathEscape(id)), opts.values())
+	return newStream(ctx, l, streamSpec[GameSummary]{path: path})
+}
+
+// ExportSwissGamesTo copies the games of a Swiss tournament straight into w,
+// in the format of opts.
+func (l *Lichess) ExportSwissGamesTo(ctx context.Context, id string, opts ExportOptions, w io.Writer) (int64, error) {
+	path := withQuery(fmt.Sprintf(swissGamesPath, url.PathEscape(id)), opts.values())
+	req, err := l.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", opts.accept())
+	return l.copyTo(req, w)
+}
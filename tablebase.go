@@ -0,0 +1,97 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+/*
+ * TABLEBASE
+ */
+
+// The endgame tablebase is served by its own host.
+const tablebaseURL = "https://tablebase.lichess.ovh"
+
+// GET
+const tablebasePath = tablebaseURL + "/%s" // Variant
+
+// Tablebase is the perfect play in an endgame, from the side to move.
+type Tablebase struct {
+	// Category is e.g. "win", "cursed-win", "draw", "blessed-loss", "loss"
+	// or "unknown" for positions out of the tablebases
+	Category string `json:"category"`
+	// DTZ is the distance to the next capture or pawn move and DTM the
+	// distance to mate, nil when unknown
+	DTZ *int `json:"dtz"`
+	PreciseDTZ *int `json:"precise_dtz"`
+	DTM *int `json:"dtm"`
+	Checkmate bool `json:"checkmate"`
+	Stalemate bool `json:"stalemate"`
+	VariantWin bool `json:"variant_win"`
+	VariantLoss bool `json:"variant_loss"`
+	InsufficientMaterial bool `json:"insufficient_material"`
+	// Moves are sorted best first
+	Moves []TablebaseMove `json:"moves"`
+}
+
+// TablebaseMove is a legal move, its Category being from the side to move
+// after it, the opponent.
+type TablebaseMove struct {
+	UCI string `json:"uci"`
+	SAN string `json:"san"`
+	Category string `json:"category"`
+	DTZ *int `json:"dtz"`
+	PreciseDTZ *int `json:"precise_dtz"`
+	DTM *int `json:"dtm"`
+	// Zeroing moves are the captures and pawn moves
+	Zeroing bool `json:"zeroing"`
+	Checkmate bool `json:"checkmate"`
+	Stalemate bool `json:"stalemate"`
+	VariantWin bool `json:"variant_win"`
+	VariantLoss bool `json:"variant_loss"`
+	InsufficientMaterial bool `json:"insufficient_material"`
+}
+
+func (t Tablebase) WDL() (int, bool) {
+	return categoryWDL(t.Category)
+}
+
+func (m TablebaseMove) WDL() (int, bool) {
+	return categoryWDL(m.Category)
+}
+
+// categoryWDL returns the Syzygy win/draw/loss value of a category: 2 for a
+// win, 1 for a win spoiled by the 50-move rule, 0 for a draw and the
+// opposites for losses. It reports false for unknown outcomes.
+func categoryWDL(category string) (int, bool) {
+	switch category {
+	case "win":
+		return 2, true
+	case "cursed-win", "maybe-win":
+		return 1, true
+	case "draw":
+		return 0, true
+	case "blessed-loss", "maybe-loss":
+		return -1, true
+	case "loss":
+		return -2, true
+	}
+	return 0, false
+}
+
+// TablebaseLookup probes the tablebases for fen, up to 7 pieces in standard
+// chess and 6 in atomic and antichess. An empty variant means standard.
+func (l *Lichess) TablebaseLookup(ctx context.Context, fen string, variant string) (Tablebase, error) {
+	switch variant {
+	case "":
+		variant = "standard"
+	case "standard", "atomic", "antichess":
+	default:
+		return Tablebase{}, fmt.Errorf("lichess: no tablebase for variant %q", variant)
+	}
+	tablebase := Tablebase{}
+	path := withQuery(fmt.Sprintf(tablebasePath, variant), url.Values{"fen": {fen}})
+	err := l.getJSON(ctx, path, &tablebase)
+	return tablebase, err
+}
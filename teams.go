@@ -0,0 +1,166 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+/*
+ * TEAMS
+ */
+
+// GET
+const teamPath = "/api/team/%s" // TeamID
+const popularTeamsPath = "/api/team/all"
+const searchTeamsPath = "/api/team/search"
+const teamsOfPath = "/api/team/of/%s" // Username
+const teamMembersPath = "/api/team/%s/users" // TeamID
+const teamJoinRequestsPath = "/api/team/%s/requests" // TeamID
+
+// POST
+const joinTeamPath = "/team/%s/join" // TeamID
+const leaveTeamPath = "/team/%s/quit" // TeamID
+const kickTeamMemberPath = "/api/team/%s/kick/%s" // TeamID, UserID
+const joinRequestPath = "/api/team/%s/request/%s/%s" // TeamID, UserID, Decision
+
+type Team struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Open teams can be joined without the approval of a leader
+	Open bool `json:"open"`
+	Leader LightUser `json:"leader"`
+	Leaders []LightUser `json:"leaders,omitempty"`
+	NbMembers int `json:"nbMembers"`
+	Location string `json:"location,omitempty"`
+}
+
+// Page is a page of a paginated listing, pages being numbered from 1.
+type Page[T any] struct {
+	CurrentPage int `json:"currentPage"`
+	MaxPerPage int `json:"maxPerPage"`
+	Results []T `json:"currentPageResults"`
+	NbResults int `json:"nbResults"`
+	NbPages int `json:"nbPages"`
+	// NextPage is nil on the last page
+	NextPage *int `json:"nextPage"`
+	PreviousPage *int `json:"previousPage"`
+}
+
+// HasNext reports whether another page follows.
+func (p Page[T]) HasNext() bool {
+	return p.NextPage != nil
+}
+
+func pageQuery(path string, v url.Values, page int) string {
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	return withQuery(path, v)
+}
+
+func (l *Lichess) GetTeam(ctx context.Context, id string) (Team, error) {
+	team := Team{}
+	err := l.getJSON(ctx, fmt.Sprintf(teamPath, url.PathEscape(id)), &team)
+	return team, err
+}
+
+// GetPopularTeams returns a page of the teams with the most members.
+func (l *Lichess) GetPopularTeams(ctx context.Context, page int) (Page[Team], error) {
+	teams := Page[Team]{}
+	err := l.getJSON(ctx, pageQuery(popularTeamsPath, url.Values{}, page), &teams)
+	return teams, err
+}
+
+// SearchTeams returns a page of the teams whose name matches text.
+func (l *Lichess) SearchTeams(ctx context.Context, text string, page int) (Page[Team], error) {
+	teams := Page[Team]{}
+	err := l.getJSON(ctx, pageQuery(searchTeamsPath, url.Values{"text": {text}}, page), &teams)
+	return teams, err
+}
+
+// GetTeamsOfPlayer returns all the teams username is a member of.
+func (l *Lichess) GetTeamsOfPlayer(ctx context.Context, username string) ([]Team, error) {
+	teams := []Team{}
+	err := l.getJSON(ctx, fmt.Sprintf(teamsOfPath, url.PathEscape(username)), &teams)
+	return teams, err
+}
+
+// StreamTeamMembers streams the profiles of the members of a team, most
+// recent members first. Teams can be huge, the members are only decoded as
+// they are received.
+func (l *Lichess) StreamTeamMembers(ctx context.Context, teamID string) *Stream[Profile] {
+	return newStream(ctx, l, streamSpec[Profile]{path: fmt.Sprintf(teamMembersPath, url.PathEscape(teamID))})
+}
+
+// JoinTeam joins a team, or asks to join it if it is not open. message is
+// shown to the leaders along with the request, password is only needed by
+// teams that have one.
+func (l *Lichess) JoinTeam(ctx context.Context, id string, message string, password string) error {
+	if err := l.authorized().requireScope(ScopeTeamWrite); err != nil {
+		return err
+	}
+	v := url.Values{}
+	if message != "" {
+		v.Set("message", message)
+	}
+	if password != "" {
+		v.Set("password", password)
+	}
+	return l.postForm(ctx, fmt.Sprintf(joinTeamPath, url.PathEscape(id)), v)
+}
+
+func (l *Lichess) LeaveTeam(ctx context.Context, id string) error {
+	if err := l.authorized().requireScope(ScopeTeamWrite); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(leaveTeamPath, url.PathEscape(id)), nil)
+}
+
+// KickTeamMember removes a member from a team led by the authenticated user.
+func (l *Lichess) KickTeamMember(ctx context.Context, teamID string, userID string) error {
+	if err := l.authorized().requireScope(ScopeTeamLead); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(kickTeamMemberPath, url.PathEscape(teamID), url.PathEscape(userID)), nil)
+}
+
+// TeamJoinRequest is a request to join a team that is not open.
+type TeamJoinRequest struct {
+	Request struct {
+		TeamID string `json:"teamId"`
+		UserID string `json:"userId"`
+		// Date is in milliseconds since the epoch
+		Date int64 `json:"date"`
+		Message string `json:"message,omitempty"`
+	} `json:"request"`
+	User Profile `json:"user"`
+}
+
+// GetTeamJoinRequests returns the pending requests to join a team led by the
+// authenticated user.
+func (l *Lichess) GetTeamJoinRequests(ctx context.Context, teamID string) ([]TeamJoinRequest, error) {
+	if err := l.authorized().requireScope(ScopeTeamRead); err != nil {
+		return nil, err
+	}
+	requests := []TeamJoinRequest{}
+	err := l.getJSON(ctx, fmt.Sprintf(teamJoinRequestsPath, url.PathEscape(teamID)), &requests)
+	return requests, err
+}
+
+func (l *Lichess) AcceptJoinRequest(ctx context.Context, teamID string, userID string) error {
+	return l.answerJoinRequest(ctx, teamID, userID, "accept")
+}
+
+func (l *Lichess) DeclineJoinRequest(ctx context.Context, teamID string, userID string) error {
+	return l.answerJoinRequest(ctx, teamID, userID, "decline")
+}
+
+func (l *Lichess) answerJoinRequest(ctx context.Context, teamID string, userID string, decision string) error {
+	if err := l.authorized().requireScope(ScopeTeamLead); err != nil {
+		return err
+	}
+	return l.postForm(ctx, fmt.Sprintf(joinRequestPath, url.PathEscape(teamID), url.PathEscape(userID), decision), nil)
+}
@@ -0,0 +1,99 @@
+package lichess
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+ * TIMELINE
+ */
+
+// GET
+const timelinePath = "/api/timeline"
+
+// Timeline entry types
+const (
+	TimelineFollow = "follow"
+	TimelineTeamJoin = "team-join"
+	TimelineTeamCreate = "team-create"
+	TimelineForumPost = "forum-post"
+	TimelineBlogPost = "blog-post"
+	TimelineUblogPost = "ublog-post"
+	TimelineUblogPostLike = "ublog-post-like"
+	TimelineTourJoin = "tour-join"
+	TimelineGameEnd = "game-end"
+	TimelineSimulCreate = "simul-create"
+	TimelineSimulJoin = "simul-join"
+	TimelineStudyLike = "study-like"
+	TimelinePlanStart = "plan-start"
+	TimelinePlanRenew = "plan-renew"
+	TimelineStreamStart = "stream-start"
+)
+
+// TimelineEntry is an event of the timeline. Only the fields of its Type are
+// set, e.g. U1 and U2 for follows or GameID, Perf, Opponent and Win for game
+// ends.
+type TimelineEntry struct {
+	Type string `json:"type"`
+	// Date is in milliseconds since the epoch
+	Date int64 `json:"date"`
+
+	// follow: U1 followed U2
+	U1 string `json:"u1,omitempty"`
+	U2 string `json:"u2,omitempty"`
+
+	// UserID is the user the entry is about, for most of them
+	UserID string `json:"userId,omitempty"`
+
+	TeamID string `json:"teamId,omitempty"`
+	TopicID string `json:"topicId,omitempty"`
+	TopicName string `json:"topicName,omitempty"`
+	PostID string `json:"postId,omitempty"`
+	TourID string `json:"tourId,omitempty"`
+	TourName string `json:"tourName,omitempty"`
+	SimulID string `json:"simulId,omitempty"`
+	SimulName string `json:"simulName,omitempty"`
+	StudyID string `json:"studyId,omitempty"`
+	StudyName string `json:"studyName,omitempty"`
+
+	// blog posts and streams
+	ID string `json:"id,omitempty"`
+	Slug string `json:"slug,omitempty"`
+	Title string `json:"title,omitempty"`
+
+	// game-end, GameID being the full ID, of the player
+	GameID string `json:"fullId,omitempty"`
+	Perf string `json:"perf,omitempty"`
+	Opponent string `json:"opponent,omitempty"`
+	// Win is nil for draws
+	Win *bool `json:"win,omitempty"`
+
+	// plan-start and plan-renew
+	Months int `json:"months,omitempty"`
+}
+
+// Timeline is a page of the timeline, with the users of the entries by
+// user ID.
+type Timeline struct {
+	Entries []TimelineEntry `json:"entries"`
+	Users map[string]LightUser `json:"users"`
+}
+
+// GetTimeline returns up to nb of the timeline entries of the authenticated
+// user since the given time, most recent first. A zero since returns the
+// latest ones, and Lichess sends 15 entries if nb is zero.
+func (l *Lichess) GetTimeline(ctx context.Context, since time.Time, nb int) (Timeline, error) {
+	v := url.Values{}
+	if !since.IsZero() {
+		v.Set("since", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+	if nb > 0 {
+		v.Set("nb", strconv.Itoa(nb))
+	}
+	timeline := Timeline{}
+	err := l.getJSON(ctx, withQuery(timelinePath, v), &timeline)
+	return timeline, err
+}
@@ -0,0 +1,136 @@
+package lichess
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+/*
+ * TIME USAGE
+ */
+
+// Games are split into phases by move number, the opening covers the first
+// ten moves and the middlegame ends after move thirty.
+const (
+	openingPlies = 20
+	middlegamePlies = 60
+)
+
+type GamePhase int
+
+const (
+	PhaseOpening GamePhase = iota
+	PhaseMiddlegame
+	PhaseEndgame
+)
+
+func (p GamePhase) String() string {
+	switch p {
+	case PhaseOpening:
+		return "opening"
+	case PhaseMiddlegame:
+		return "middlegame"
+	}
+	return "endgame"
+}
+
+func phaseOf(ply int) GamePhase {
+	switch {
+	case ply < openingPlies:
+		return PhaseOpening
+	case ply < middlegamePlies:
+		return PhaseMiddlegame
+	}
+	return PhaseEndgame
+}
+
+// MoveTime is the time spent on a single move.
+type MoveTime struct {
+	// Ply is the index of the move, 0 for white's first move
+	Ply int
+	Color string
+	Phase GamePhase
+	// Remaining is the clock of the player after the move
+	Remaining time.Duration
+	Spent time.Duration
+	// TimeTrouble is set when less than a tenth of the initial time was left
+	TimeTrouble bool
+}
+
+// TimeUsage summarizes how both players used their clock.
+type TimeUsage struct {
+	Moves []MoveTime
+	// Average time per move, indexed by phase, for each color
+	White [3]time.Duration
+	Black [3]time.Duration
+	// TimeTrouble is the first ply played in time trouble by each color, -1
+	// if it never happened
+	WhiteTimeTrouble int
+	BlackTimeTrouble int
+}
+
+// AnalyzeTime computes the time spent on every move from the remaining clock
+// times after each ply, as found in %clk comments or tracked live.
+func AnalyzeTime(initial time.Duration, increment time.Duration, clocks []time.Duration) TimeUsage {
+	usage := TimeUsage{WhiteTimeTrouble: -1, BlackTimeTrouble: -1}
+	prev := [2]time.Duration{initial, initial}
+	var total [2][3]time.Duration
+	var count [2][3]int
+
+	for ply, remaining := range clocks {
+		side := ply % 2
+		// the increment is added once the move is made
+		spent := prev[side] + increment - remaining
+		if spent < 0 {
+			spent = 0
+		}
+		prev[side] = remaining
+
+		m := MoveTime{
+			Ply: ply,
+			Color: "white",
+			Phase: phaseOf(ply),
+			Remaining: remaining,
+			Spent: spent,
+			TimeTrouble: remaining < initial/10,
+		}
+		trouble := &usage.WhiteTimeTrouble
+		if side == 1 {
+			m.Color = "black"
+			trouble = &usage.BlackTimeTrouble
+		}
+		if m.TimeTrouble && *trouble < 0 {
+			*trouble = ply
+		}
+		total[side][m.Phase] += spent
+		count[side][m.Phase]++
+		usage.Moves = append(usage.Moves, m)
+	}
+
+	for phase := range total[0] {
+		if count[0][phase] > 0 {
+			usage.White[phase] = total[0][phase] / time.Duration(count[0][phase])
+		}
+		if count[1][phase] > 0 {
+			usage.Black[phase] = total[1][phase] / time.Duration(count[1][phase])
+		}
+	}
+	return usage
+}
+
+var clkComment = regexp.MustCompile(`\[%clk (\d+):(\d{1,2}):(\d{1,2}(?:\.\d+)?)\]`)
+
+// ParseClockComments returns the %clk times of a PGN in move order.
+func ParseClockComments(pgn string) []time.Duration {
+	var clocks []time.Duration
+	for _, m := range clkComment.FindAllStringSubmatch(pgn, -1) {
+		h, _ := strconv.Atoi(m[1])
+		min, _ := strconv.Atoi(m[2])
+		sec, _ := strconv.ParseFloat(m[3], 64)
+		d := time.Duration(h)*time.Hour + time.Duration(min)*time.Minute +
+			time.Duration(sec*float64(time.Second))
+		clocks = append(clocks, d)
+	}
+	return clocks
+}
@@ -0,0 +1,250 @@
+package lichess
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/99designs/keyring"
+	"golang.org/x/oauth2"
+)
+
+/*
+ * TOKEN STORE
+ */
+
+// ErrNoToken is returned by TokenStore.Load when no token was saved yet.
+var ErrNoToken = errors.New("lichess: no stored token")
+
+// TokenStore persists the OAuth token between runs.
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	Delete() error
+}
+
+const tokenKey = "token"
+
+// KeyringTokenStore keeps the token in the OS keyring (macOS keychain,
+// Windows credentials, Secret Service, KWallet or pass).
+type KeyringTokenStore struct {
+	Config keyring.Config
+}
+
+// NewKeyringTokenStore returns a store using the keyring of service.
+func NewKeyringTokenStore(service string) *KeyringTokenStore {
+	return &KeyringTokenStore{Config: keyring.Config{
+		ServiceName: service,
+		KeychainName: service,
+		KeychainTrustApplication: true,
+		KWalletAppID: service,
+		KWalletFolder: service,
+		WinCredPrefix: service,
+		LibSecretCollectionName: service,
+		PassPrefix: service,
+		// the encrypted file fallback would prompt on stdin for a password
+		AllowedBackends: []keyring.BackendType{
+			keyring.KeychainBackend,
+			keyring.WinCredBackend,
+			keyring.SecretServiceBackend,
+			keyring.KWalletBackend,
+			keyring.PassBackend,
+		},
+	}}
+}
+
+func (s *KeyringTokenStore) Load() (*oauth2.Token, error) {
+	ring, err := keyring.Open(s.Config)
+	if err != nil {
+		return nil, err
+	}
+	item, err := ring.Get(tokenKey)
+	if err == keyring.ErrKeyNotFound {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(item.Data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *KeyringTokenStore) Save(token *oauth2.Token) error {
+	ring, err := keyring.Open(s.Config)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return ring.Set(keyring.Item{Key: tokenKey, Data: data})
+}
+
+func (s *KeyringTokenStore) Delete() error {
+	ring, err := keyring.Open(s.Config)
+	if err != nil {
+		return err
+	}
+	if err := ring.Remove(tokenKey); err != nil && err != keyring.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// FileTokenStore keeps the token as JSON in a file only readable by the
+// current user.
+type FileTokenStore struct {
+	Path string
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("lichess: token file %s is corrupted: %w", s.Path, err)
+	}
+	return token, nil
+}
+
+// Save replaces the file atomically, creating its directory if needed.
+func (s *FileTokenStore) Save(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	_, err = atomicFile(s.Path).Write(data)
+	return err
+}
+
+func (s *FileTokenStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// EncryptedFileTokenStore writes the token to a file encrypted with AES-GCM,
+// for systems without a keyring. The key is supplied by the application and
+// must be 16, 24 or 32 bytes long.
+type EncryptedFileTokenStore struct {
+	Path string
+	aead cipher.AEAD
+}
+
+func NewEncryptedFileTokenStore(path string, key []byte) (*EncryptedFileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedFileTokenStore{Path: path, aead: aead}, nil
+}
+
+func (s *EncryptedFileTokenStore) Load() (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	size := s.aead.NonceSize()
+	if len(data) < size {
+		return nil, fmt.Errorf("lichess: token file %s is corrupted", s.Path)
+	}
+	plain, err := s.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("lichess: cannot decrypt token file %s: %w", s.Path, err)
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(plain, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Save replaces the file atomically, it is only readable by the current user.
+func (s *EncryptedFileTokenStore) Save(token *oauth2.Token) error {
+	plain, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	_, err = atomicFile(s.Path).Write(s.aead.Seal(nonce, nonce, plain, nil))
+	return err
+}
+
+func (s *EncryptedFileTokenStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// savingTokenSource saves the tokens of src to store whenever they are
+// refreshed.
+type savingTokenSource struct {
+	src oauth2.TokenSource
+	store TokenStore
+
+	mu sync.Mutex
+	last string
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.last {
+		// the new token works even if it could not be saved
+		if s.store.Save(token) == nil {
+			s.last = token.AccessToken
+		}
+	}
+	return token, nil
+}
+
+// newStoredClient returns a client using token, which refreshes it as
+// necessary and saves the refreshed tokens to store.
+func newStoredClient(ctx context.Context, conf *oauth2.Config, store TokenStore, token *oauth2.Token) *AuthorizedClient {
+	src := &savingTokenSource{src: conf.TokenSource(ctx, token), store: store, last: token.AccessToken}
+	return &AuthorizedClient{
+		Client: oauth2.NewClient(ctx, src),
+		Token: token,
+		scopes: tokenScopes(token, conf.Scopes),
+	}
+}
@@ -0,0 +1,48 @@
+package lichess
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+/*
+ * TRANSPORT
+ */
+
+// TransportConfig tunes the connection pool used to talk to Lichess. Bots
+// following many game streams at once mostly need a higher
+// MaxIdleConnsPerHost, the net/http default of 2 makes every extra
+// concurrent request open and tear down its own connection.
+type TransportConfig struct {
+	// MaxConnsPerHost caps connections to a single host, 0 means no limit
+	MaxConnsPerHost int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 falls back to one connection per in-flight request
+	DisableHTTP2 bool
+}
+
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConnsPerHost: 64,
+	IdleConnTimeout: 90 * time.Second,
+}
+
+// NewTransport returns a transport configured by conf. HTTP/2 is attempted
+// by default so that concurrent streams are multiplexed over a single
+// connection.
+func NewTransport(conf TransportConfig) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxConnsPerHost = conf.MaxConnsPerHost
+	tr.MaxIdleConnsPerHost = conf.MaxIdleConnsPerHost
+	tr.MaxIdleConns = 0
+	if conf.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = conf.IdleConnTimeout
+	}
+	tr.ForceAttemptHTTP2 = !conf.DisableHTTP2
+	if conf.DisableHTTP2 {
+		// a non-nil empty map is how net/http is told not to upgrade
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return tr
+}
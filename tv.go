@@ -0,0 +1,104 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+/*
+ * TV
+ */
+
+// GET
+const tvChannelsPath = "/api/tv/channels"
+const tvChannelGamesPath = "/api/tv/%s" // Channel
+const tvFeedPath = "/api/tv/feed"
+const tvChannelFeedPath = "/api/tv/%s/feed" // Channel
+
+// TVChannel is the game currently featured on a TV channel.
+type TVChannel struct {
+	User LightUser `json:"user"`
+	Rating int `json:"rating"`
+	GameID string `json:"gameId"`
+	// Color is the side of User, shown at the bottom of the board
+	Color string `json:"color"`
+}
+
+// GetTVChannels returns the featured game of every channel, by channel name,
+// e.g. "Blitz" or "Bot".
+func (l *Lichess) GetTVChannels(ctx context.Context) (map[string]TVChannel, error) {
+	channels := map[string]TVChannel{}
+	err := l.getJSON(ctx, tvChannelsPath, &channels)
+	return channels, err
+}
+
+// GetTVChannelGames streams the nb best ongoing games of channel, its key
+// being e.g. "blitz", "bot" or "kingOfTheHill". Lichess sends 10 games if nb
+// is zero, and at most 30.
+func (l *Lichess) GetTVChannelGames(ctx context.Context, channel string, nb int, opts ExportOptions) *Stream[GameSummary] {
+	v := opts.values()
+	if nb > 0 {
+		v.Set("nb", strconv.Itoa(nb))
+	}
+	path := withQuery(fmt.Sprintf(tvChannelGamesPath, url.PathEscape(channel)), v)
+	return newStream(ctx, l, streamSpec[GameSummary]{path: path})
+}
+
+// TVFeedEvent is a message of a TV feed, of Type "featured" when a new game
+// is featured and "fen" after every move.
+type TVFeedEvent struct {
+	Type string `json:"t"`
+	Data TVFeedData `json:"d"`
+}
+
+type TVFeedData struct {
+	// Game description, sent with featured messages
+	ID string `json:"id,omitempty"`
+	Orientation string `json:"orientation,omitempty"`
+	Players []TVFeedPlayer `json:"players,omitempty"`
+
+	// Position, sent with every message
+	FEN string `json:"fen"`
+	LastMove string `json:"lm,omitempty"`
+	// Remaining clock times in seconds
+	WhiteClock int `json:"wc,omitempty"`
+	BlackClock int `json:"bc,omitempty"`
+}
+
+type TVFeedPlayer struct {
+	Color string `json:"color"`
+	User LightUser `json:"user"`
+	Rating int `json:"rating"`
+	Seconds int `json:"seconds"`
+}
+
+// StreamTVFeed sends the moves of the game featured on Lichess TV, and the
+// next featured game once it ends, until ctx is done. Dropped connections
+// are reestablished, the feed then starts again with a featured message.
+func (l *Lichess) StreamTVFeed(ctx context.Context, events chan<- TVFeedEvent) error {
+	return runStream(ctx, l, streamSpec[TVFeedEvent]{path: tvFeedPath, reconnect: true}, events)
+}
+
+// TVFeedStream follows the Lichess TV feed like StreamTVFeed.
+func (l *Lichess) TVFeedStream(ctx context.Context) *Stream[TVFeedEvent] {
+	return newStream(ctx, l, streamSpec[TVFeedEvent]{path: tvFeedPath, reconnect: true})
+}
+
+// StreamTVChannelFeed is StreamTVFeed for the games of a single channel,
+// e.g. "blitz" or "bot".
+func (l *Lichess) StreamTVChannelFeed(ctx context.Context, channel string, events chan<- TVFeedEvent) error {
+	return runStream(ctx, l, tvChannelSpec(channel), events)
+}
+
+// TVChannelFeedStream follows the feed of a single channel like
+// StreamTVChannelFeed.
+func (l *Lichess) TVChannelFeedStream(ctx context.Context, channel string) *Stream[TVFeedEvent] {
+	return newStream(ctx, l, tvChannelSpec(channel))
+}
+
+func tvChannelSpec(channel string) streamSpec[TVFeedEvent] {
+	path := fmt.Sprintf(tvChannelFeedPath, url.PathEscape(channel))
+	return streamSpec[TVFeedEvent]{path: path, reconnect: true}
+}
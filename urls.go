@@ -0,0 +1,154 @@
+package lichess
+
+import (
+	"net/url"
+	"strings"
+)
+
+/*
+ * URLS
+ */
+
+// gameIDLength is the length of a public game ID, full IDs returned to
+// players carry 4 extra characters identifying their side.
+const gameIDLength = 8
+
+func publicGameID(id string) string {
+	if len(id) > gameIDLength {
+		return id[:gameIDLength]
+	}
+	return id
+}
+
+// GameURL links to a game, seen from black's side if color is "black".
+func GameURL(gameID string, color string) string {
+	u := lichessURL + "/" + url.PathEscape(publicGameID(gameID))
+	if color == "black" {
+		u += "/black"
+	}
+	return u
+}
+
+// AnalysisURL links to the analysis board set up at fen. An empty variant
+// means standard and an empty fen the initial position.
+func AnalysisURL(variant string, fen string, color string) string {
+	if variant == "" {
+		variant = "standard"
+	}
+	u := lichessURL + "/analysis/" + url.PathEscape(variant)
+	if fen != "" {
+		u += "/" + fenPath(fen)
+	}
+	if color == "black" {
+		u += "?color=black"
+	}
+	return u
+}
+
+// AnalysisPGNURL links to the analysis board with the moves of pgn played
+// out. Only the movetext is used, tags and comments are dropped.
+func AnalysisPGNURL(pgn string) string {
+	moves := []string{}
+	depth := 0
+	for _, line := range strings.Split(pgn, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			continue
+		}
+		for _, tok := range strings.Fields(line) {
+			if strings.HasPrefix(tok, "{") || strings.HasPrefix(tok, "(") {
+				depth++
+			}
+			if depth == 0 && !isPGNNoise(tok) {
+				moves = append(moves, url.PathEscape(tok))
+			}
+			if strings.HasSuffix(tok, "}") || strings.HasSuffix(tok, ")") {
+				depth--
+			}
+		}
+	}
+	return lichessURL + "/analysis/pgn/" + strings.Join(moves, "_")
+}
+
+func isPGNNoise(tok string) bool {
+	switch tok {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return true
+	}
+	return strings.HasSuffix(tok, ".") || strings.HasPrefix(tok, "$")
+}
+
+// fenPath encodes fen the way Lichess expects it in paths, the FEN alphabet
+// only needs its spaces replaced.
+func fenPath(fen string) string {
+	return strings.Join(strings.Fields(fen), "_")
+}
+
+// ChallengeURL links to the page where a challenge can be accepted.
+func ChallengeURL(challengeID string) string {
+	return lichessURL + "/" + url.PathEscape(challengeID)
+}
+
+func TournamentURL(tournamentID string) string {
+	return lichessURL + "/tournament/" + url.PathEscape(tournamentID)
+}
+
+func SwissURL(swissID string) string {
+	return lichessURL + "/swiss/" + url.PathEscape(swissID)
+}
+
+func StudyURL(studyID string) string {
+	return lichessURL + "/study/" + url.PathEscape(studyID)
+}
+
+func StudyChapterURL(studyID string, chapterID string) string {
+	return StudyURL(studyID) + "/" + url.PathEscape(chapterID)
+}
+
+func UserURL(username string) string {
+	return lichessURL + "/@/" + url.PathEscape(username)
+}
+
+// EmbedOptions customise the look of embedded iframes. Empty values let
+// Lichess pick ("auto").
+type EmbedOptions struct {
+	Theme string
+	Bg string
+}
+
+func (o EmbedOptions) query() string {
+	v := url.Values{}
+	if o.Theme != "" {
+		v.Set("theme", o.Theme)
+	}
+	if o.Bg != "" {
+		v.Set("bg", o.Bg)
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+// EmbedGameURL is the iframe source of a game viewer.
+func EmbedGameURL(gameID string, color string, opts EmbedOptions) string {
+	u := lichessURL + "/embed/game/" + url.PathEscape(publicGameID(gameID))
+	if color == "black" {
+		u += "/black"
+	}
+	return u + opts.query()
+}
+
+// EmbedStudyChapterURL is the iframe source of a study chapter viewer.
+func EmbedStudyChapterURL(studyID string, chapterID string, opts EmbedOptions) string {
+	return lichessURL + "/study/embed/" + url.PathEscape(studyID) + "/" + url.PathEscape(chapterID) + opts.query()
+}
+
+// EmbedTVURL is the iframe source of the Lichess TV widget.
+func EmbedTVURL(opts EmbedOptions) string {
+	return lichessURL + "/tv/frame" + opts.query()
+}
+
+// EmbedPuzzleURL is the iframe source of the daily puzzle widget.
+func EmbedPuzzleURL(opts EmbedOptions) string {
+	return lichessURL + "/training/frame" + opts.query()
+}
@@ -0,0 +1,102 @@
+package lichess
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+ * USERS
+ */
+
+// GET
+const userPath = "/api/user/%s" // Username
+const autocompletePath = "/api/player/autocomplete"
+const notePath = "/api/user/%s/note" // Username, also POSTed to
+
+// POST
+const usersPath = "/api/users"
+
+// Lichess returns at most this many profiles per GetUsers call.
+const MaxUserIDs = 300
+
+// GetUser returns the public profile of a user.
+func (l *Lichess) GetUser(ctx context.Context, username string) (Profile, error) {
+	profile := Profile{}
+	err := l.getJSON(ctx, fmt.Sprintf(userPath, url.PathEscape(username)), &profile)
+	return profile, err
+}
+
+// GetUsers returns the public profiles of up to MaxUserIDs users at once.
+// Unknown users and closed accounts are left out.
+func (l *Lichess) GetUsers(ctx context.Context, ids []string) ([]Profile, error) {
+	if len(ids) > MaxUserIDs {
+		return nil, fmt.Errorf("lichess: at most %d users can be fetched at once, got %d", MaxUserIDs, len(ids))
+	}
+	profiles := []Profile{}
+	err := l.postTextJSON(ctx, usersPath, strings.Join(ids, ","), &profiles)
+	return profiles, err
+}
+
+// AutocompletePlayer is a suggestion of AutocompletePlayerObjects.
+type AutocompletePlayer struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Patron bool `json:"patron,omitempty"`
+	Online bool `json:"online"`
+}
+
+func autocompleteValues(term string, friendsOnly bool) url.Values {
+	v := url.Values{"term": {term}}
+	if friendsOnly {
+		v.Set("friend", "1")
+	}
+	return v
+}
+
+// AutocompletePlayers returns the names of the players starting with term,
+// which must be at least 3 characters long. friendsOnly restricts the
+// suggestions to the users followed by the authenticated user.
+func (l *Lichess) AutocompletePlayers(ctx context.Context, term string, friendsOnly bool) ([]string, error) {
+	names := []string{}
+	err := l.getJSON(ctx, withQuery(autocompletePath, autocompleteValues(term, friendsOnly)), &names)
+	return names, err
+}
+
+// AutocompletePlayerObjects is AutocompletePlayers with the title and online
+// status of each player.
+func (l *Lichess) AutocompletePlayerObjects(ctx context.Context, term string, friendsOnly bool) ([]AutocompletePlayer, error) {
+	v := autocompleteValues(term, friendsOnly)
+	v.Set("object", "true")
+	resp := struct {
+		Result []AutocompletePlayer `json:"result"`
+	}{}
+	err := l.getJSON(ctx, withQuery(autocompletePath, v), &resp)
+	return resp.Result, err
+}
+
+// Note is a private note of the authenticated user about another user.
+type Note struct {
+	From LightUser `json:"from"`
+	To LightUser `json:"to"`
+	Text string `json:"text"`
+	// Date is in milliseconds since the epoch
+	Date int64 `json:"date"`
+}
+
+// GetNote returns the notes the authenticated user wrote about username,
+// most recent first.
+func (l *Lichess) GetNote(ctx context.Context, username string) ([]Note, error) {
+	notes := []Note{}
+	err := l.getJSON(ctx, fmt.Sprintf(notePath, url.PathEscape(username)), &notes)
+	return notes, err
+}
+
+// WriteNote adds a note about username, only visible to the authenticated
+// user.
+func (l *Lichess) WriteNote(ctx context.Context, username string, text string) error {
+	return l.postForm(ctx, fmt.Sprintf(notePath, url.PathEscape(username)), url.Values{"text": {text}})
+}